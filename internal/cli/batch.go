@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/Lynthar/mkQR/internal/encoder"
 	"github.com/Lynthar/mkQR/internal/qr"
@@ -15,7 +16,6 @@ import (
 var (
 	batchOutputDir string
 	batchPrefix    string
-	batchFormat    string
 )
 
 var batchCmd = &cobra.Command{
@@ -29,6 +29,7 @@ Empty lines and lines starting with # are skipped.
 Examples:
   mkqr batch urls.txt -O ./qrcodes/
   mkqr batch nodes.txt --output-dir ./out --prefix "node_"
+  mkqr batch urls.txt -O ./vector/ --format svg
   cat links.txt | mkqr batch - -O ./out/`,
 	Args: cobra.ExactArgs(1),
 	RunE: runBatch,
@@ -37,9 +38,6 @@ Examples:
 func init() {
 	batchCmd.Flags().StringVarP(&batchOutputDir, "output-dir", "O", ".", "Output directory")
 	batchCmd.Flags().StringVar(&batchPrefix, "prefix", "qr_", "Filename prefix")
-	// Note: currently only PNG is supported, flag hidden to avoid confusion
-	batchCmd.Flags().StringVar(&batchFormat, "format", "png", "Output format")
-	batchCmd.Flags().MarkHidden("format")
 
 	rootCmd.AddCommand(batchCmd)
 }
@@ -47,16 +45,6 @@ func init() {
 func runBatch(cmd *cobra.Command, args []string) error {
 	inputFile := args[0]
 
-	// Validate size
-	if outputSize <= 0 {
-		return fmt.Errorf("size must be a positive number, got %d", outputSize)
-	}
-
-	// Create output directory
-	if err := os.MkdirAll(batchOutputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
 	// Open input file (or stdin if "-")
 	var scanner *bufio.Scanner
 	if inputFile == "-" {
@@ -70,58 +58,122 @@ func runBatch(cmd *cobra.Command, args []string) error {
 		scanner = bufio.NewScanner(file)
 	}
 
-	// Parse error correction level
-	level, err := qr.ParseLevel(errorLevel)
+	var items []batchItem
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, batchItem{content: line, source: fmt.Sprintf("line %d", lineNum)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading input: %w", err)
+	}
+
+	return renderBatch(cmd, items, batchOutputDir, batchPrefix)
+}
+
+// batchItem is one code of a batch: its content, where it came from for
+// messages, and an optional name for its file
+type batchItem struct {
+	content string
+	source  string
+	name    string
+}
+
+// renderBatch saves a code for each item in outputDir. Files are named
+// prefix plus the item name, or prefix plus a sequence number for unnamed
+// items. Items that fail are reported and skipped.
+func renderBatch(cmd *cobra.Command, items []batchItem, outputDir, prefix string) error {
+	opts, err := buildOptions()
 	if err != nil {
 		return err
 	}
 
-	opts := qr.Options{
-		Level: level,
-		Size:  outputSize,
+	// Create output directory
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Validate format (file formats only, PNG by default)
+	format := qr.FormatPNG
+	if outputFormat != "" {
+		format, err = qr.ParseFormat(outputFormat)
+		if err != nil {
+			return err
+		}
+	}
+	if format != qr.FormatPNG && format != qr.FormatSVG && format != qr.FormatPDF {
+		return fmt.Errorf("unsupported batch format: %s (use png, svg, or pdf)", format)
+	}
+	if err := checkLogoFormat(format); err != nil {
+		return err
 	}
+
 	gen := qr.NewGenerator(opts)
 
 	count := 0
-	lineNum := 0
+	unverified := 0
+	usedNames := make(map[string]bool)
 
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+	for _, item := range items {
+		// Detect content type for logging
+		contentType, _, problems := encoder.DetectAndDescribe(item.content)
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		if err := checkProxy(problems); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error on %s: %v\n", item.source, err)
 			continue
 		}
 
-		// Detect content type for logging
-		contentType, _ := encoder.DetectAndDescribe(line)
-
 		// Add https:// for URLs without protocol
-		content := line
-		if contentType == encoder.TypeURL && !strings.HasPrefix(strings.ToLower(line), "http") {
-			content = "https://" + line
+		content := item.content
+		if contentType == encoder.TypeURL && !strings.HasPrefix(strings.ToLower(content), "http") {
+			content = "https://" + content
 		}
 
 		// Generate QR code
 		qrCode, err := gen.Generate(content)
 		if err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "Error on line %d: %v\n", lineNum, err)
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error on %s: %v\n", item.source, err)
 			continue
 		}
 
+		if verify {
+			if err := qr.Verify(qrCode, content, outputSize); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error on %s: %v\n", item.source, err)
+				unverified++
+				continue
+			}
+		}
+
 		// Save to file
-		filename := filepath.Join(batchOutputDir, fmt.Sprintf("%s%04d.%s", batchPrefix, count+1, batchFormat))
-		if err := qr.SavePNG(qrCode, filename, outputSize); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "Error saving line %d: %v\n", lineNum, err)
+		base := fmt.Sprintf("%s%04d", prefix, count+1)
+		if name := safeFilename(item.name); name != "" {
+			base = prefix + name
+			for i := 2; usedNames[base]; i++ {
+				base = fmt.Sprintf("%s%s_%d", prefix, name, i)
+			}
+		}
+		usedNames[base] = true
+		filename := filepath.Join(outputDir, base+"."+string(format))
+		if err := saveQR(qrCode, filename, format); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error saving %s: %v\n", item.source, err)
 			continue
 		}
 
 		if !quiet {
 			// Truncate long content for display
-			preview := line
-			if len(preview) > 40 {
-				preview = preview[:40] + "..."
+			preview := item.content
+			if item.name != "" {
+				preview = item.name
+			}
+			if r := []rune(preview); len(r) > 40 {
+				preview = string(r[:40]) + "..."
 			}
 			fmt.Fprintf(cmd.ErrOrStderr(), "[%d] %s -> %s\n", count+1, preview, filename)
 		}
@@ -129,13 +181,33 @@ func runBatch(cmd *cobra.Command, args []string) error {
 		count++
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading input: %w", err)
+	if !quiet {
+		fmt.Fprintf(cmd.ErrOrStderr(), "\nGenerated %d QR codes in %s\n", count, outputDir)
 	}
 
-	if !quiet {
-		fmt.Fprintf(cmd.ErrOrStderr(), "\nGenerated %d QR codes in %s\n", count, batchOutputDir)
+	if unverified > 0 {
+		return fmt.Errorf("%d QR codes failed verification and were not saved", unverified)
 	}
 
 	return nil
 }
+
+// safeFilename reduces name to letters, digits, dots, dashes and
+// underscores, replacing runs of anything else (spaces, slashes, emoji)
+// with a single underscore
+func safeFilename(name string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	return strings.Trim(b.String(), ".")
+}
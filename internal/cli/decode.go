@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"os"
+
+	"github.com/Lynthar/mkQR/internal/encoder"
+	"github.com/Lynthar/mkQR/internal/qr"
+	"github.com/spf13/cobra"
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode <image>...",
+	Short: "Read QR codes from image files",
+	Long: `Read QR codes from PNG, JPEG or GIF images.
+
+Each decoded payload is printed on stdout. Unless --quiet is given, the
+position, version and error correction level of each code and the
+detected content type are printed on stderr. Images may contain several
+codes; use "-" to read an image from stdin.
+
+Examples:
+  mkqr decode qr.png
+  mkqr decode photo.jpg screenshot.png
+  mkqr decode -q wifi.png > payload.txt
+  curl -s https://example.com/qr.png | mkqr decode -`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDecode,
+}
+
+func init() {
+	rootCmd.AddCommand(decodeCmd)
+}
+
+func runDecode(cmd *cobra.Command, args []string) error {
+	for _, path := range args {
+		img, err := loadImage(path)
+		if err != nil {
+			return err
+		}
+
+		results, err := qr.Decode(img)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		for i, r := range results {
+			if !quiet {
+				name := ""
+				if len(args) > 1 {
+					name = path + ": "
+				}
+				b := r.Bounds()
+				fmt.Fprintf(os.Stderr, "%sCode %d of %d: version %d-%s at (%d,%d)-(%d,%d)\n",
+					name, i+1, len(results), r.Version, r.Level, b.Min.X, b.Min.Y, b.Max.X, b.Max.Y)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), r.Content)
+
+			if !quiet {
+				_, description, _ := encoder.DetectAndDescribe(r.Content)
+				fmt.Fprintf(os.Stderr, "Detected: %s\n", description)
+			}
+		}
+	}
+	return nil
+}
+
+// loadImage decodes an image file, or stdin when path is "-"
+func loadImage(path string) (image.Image, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open image: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Lynthar/mkQR/internal/encoder"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventSummary       string
+	eventStart         string
+	eventEnd           string
+	eventDuration      time.Duration
+	eventAllDay        bool
+	eventTimeZone      string
+	eventLocation      string
+	eventDescription   string
+	eventURL           string
+	eventOrganizer     string
+	eventOrganizerName string
+	eventAlarms        []string
+)
+
+// eventTimeLayouts are the accepted --start/--end formats, most specific
+// first; the date-only layout marks an all-day event
+var eventTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var eventCmd = &cobra.Command{
+	Use:   "event",
+	Short: "Generate QR code for a calendar event",
+	Long: `Generate a QR code containing a calendar event (iCalendar VEVENT).
+
+Scanning it offers to add the event to the phone's calendar. Times are
+given as "YYYY-MM-DD HH:MM" in the --tz time zone (default: local time),
+or in RFC 3339 form with an offset, and are stored as UTC. A date without a time
+makes an all-day event; --end is then the last day of the event.
+
+Examples:
+  mkqr event -s "Go Meetup" --start "2026-10-17 18:30" --duration 2h --location "Room 1"
+  mkqr event -s "Launch" --start 2026-10-17T09:00:00Z --end 2026-10-17T10:00:00Z --alarm 15m
+  mkqr event -s "Conference" --start 2026-11-02 --end 2026-11-04 --url https://example.com
+  mkqr event -s "Standup" --start "2026-10-19 09:30" --tz Europe/Berlin --organizer team@example.com`,
+	RunE: runEvent,
+}
+
+func init() {
+	eventCmd.Flags().StringVarP(&eventSummary, "summary", "s", "", "Event title [required]")
+	eventCmd.Flags().StringVar(&eventStart, "start", "", "Start time (YYYY-MM-DD HH:MM, RFC 3339, or YYYY-MM-DD for all day) [required]")
+	eventCmd.Flags().StringVar(&eventEnd, "end", "", "End time, or last day of an all-day event")
+	eventCmd.Flags().DurationVar(&eventDuration, "duration", 0, "Length of the event instead of --end (e.g. 90m, 2h)")
+	eventCmd.Flags().BoolVar(&eventAllDay, "all-day", false, "All-day event (times in --start/--end are ignored)")
+	eventCmd.Flags().StringVar(&eventTimeZone, "tz", "", "Time zone of --start/--end (e.g. Europe/Berlin) (default local)")
+	eventCmd.Flags().StringVar(&eventLocation, "location", "", "Location")
+	eventCmd.Flags().StringVarP(&eventDescription, "description", "d", "", "Description")
+	eventCmd.Flags().StringVarP(&eventURL, "url", "u", "", "Event URL")
+	eventCmd.Flags().StringVar(&eventOrganizer, "organizer", "", "Organizer email address")
+	eventCmd.Flags().StringVar(&eventOrganizerName, "organizer-name", "", "Organizer display name")
+	eventCmd.Flags().StringSliceVar(&eventAlarms, "alarm", nil, "Reminder before the start (e.g. 15m, 1h); repeatable")
+
+	eventCmd.MarkFlagRequired("summary")
+	eventCmd.MarkFlagRequired("start")
+
+	rootCmd.AddCommand(eventCmd)
+}
+
+func runEvent(cmd *cobra.Command, args []string) error {
+	loc := time.Local
+	if eventTimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(eventTimeZone); err != nil {
+			return fmt.Errorf("invalid time zone %q: %w", eventTimeZone, err)
+		}
+	}
+
+	start, dateOnly, err := parseEventTime(eventStart, loc)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	allDay := eventAllDay || dateOnly
+
+	if eventEnd != "" && eventDuration != 0 {
+		return fmt.Errorf("use either --end or --duration, not both")
+	}
+	var end time.Time
+	switch {
+	case eventEnd != "":
+		if end, _, err = parseEventTime(eventEnd, loc); err != nil {
+			return fmt.Errorf("invalid end: %w", err)
+		}
+	case eventDuration < 0:
+		return fmt.Errorf("duration must be positive, got %s", eventDuration)
+	case eventDuration > 0 && allDay:
+		// Whole days only: the last day is the one the duration ends in
+		end = start.Add(eventDuration - time.Nanosecond)
+	case eventDuration > 0:
+		end = start.Add(eventDuration)
+	}
+
+	if allDay {
+		start = truncateDay(start)
+		if !end.IsZero() {
+			end = truncateDay(end)
+		}
+	}
+	if !end.IsZero() && (end.Before(start) || (!allDay && end.Equal(start))) {
+		return fmt.Errorf("end must be after start")
+	}
+
+	var alarms []time.Duration
+	for _, a := range eventAlarms {
+		d, err := time.ParseDuration(a)
+		if err != nil {
+			return fmt.Errorf("invalid alarm %q: %w", a, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("alarm must be a time before the start, got %s", a)
+		}
+		alarms = append(alarms, d)
+	}
+
+	event := &encoder.Event{
+		Summary:       eventSummary,
+		Start:         start,
+		End:           end,
+		AllDay:        allDay,
+		Location:      eventLocation,
+		Description:   eventDescription,
+		URL:           eventURL,
+		Organizer:     eventOrganizer,
+		OrganizerName: eventOrganizerName,
+		Alarms:        alarms,
+	}
+
+	content := event.Encode()
+
+	if !quiet {
+		when := start.Format("Mon 2 Jan 2006 15:04 MST")
+		if allDay {
+			when = start.Format("Mon 2 Jan 2006")
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Event: %s (%s)\n", eventSummary, when)
+	}
+
+	return generateQR(content)
+}
+
+// parseEventTime parses s in one of eventTimeLayouts in loc, or as RFC
+// 3339, reporting whether s was a date without a time
+func parseEventTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date (YYYY-MM-DD) or time (YYYY-MM-DD HH:MM)", s)
+}
+
+// truncateDay returns midnight at the start of t's day in t's location
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Lynthar/mkQR/internal/encoder"
+	"github.com/Lynthar/mkQR/internal/qr"
+	"github.com/spf13/cobra"
+)
+
+var inspectImage string
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [payload]",
+	Short: "Explain a QR payload field by field",
+	Long: `Explain a QR code payload: its detected type, every parsed field, and
+warnings where it departs from the format's specification (short WPA
+passphrases, weak OTP secrets, missing vCard properties, ...).
+
+The payload is taken from the argument, from stdin, or from the QR codes
+in an image given with --image.
+
+Examples:
+  mkqr inspect 'WIFI:T:WPA;S:Home;P:secret;;'
+  mkqr inspect --image wifi.png
+  mkqr decode -q photo.jpg | mkqr inspect`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().StringVarP(&inspectImage, "image", "i", "", "Decode the payload from a PNG, JPEG or GIF image")
+
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	var payloads []string
+
+	switch {
+	case inspectImage != "":
+		if len(args) > 0 {
+			return fmt.Errorf("give either a payload or --image, not both")
+		}
+		img, err := loadImage(inspectImage)
+		if err != nil {
+			return err
+		}
+		results, err := qr.Decode(img)
+		if err != nil {
+			return fmt.Errorf("%s: %w", inspectImage, err)
+		}
+		for _, r := range results {
+			payloads = append(payloads, r.Content)
+		}
+	case len(args) > 0:
+		payloads = []string{args[0]}
+	default:
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) != 0 {
+			return cmd.Help()
+		}
+		data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+		if err != nil {
+			return fmt.Errorf("failed to read from stdin: %w", err)
+		}
+		payloads = []string{strings.TrimSpace(string(data))}
+	}
+
+	out := cmd.OutOrStdout()
+	for i, payload := range payloads {
+		if payload == "" {
+			return fmt.Errorf("no content provided")
+		}
+		if len(payloads) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "Code %d of %d\n", i+1, len(payloads))
+		}
+
+		inspection, err := encoder.Inspect(payload)
+		if err != nil {
+			return err
+		}
+		printInspection(out, inspection)
+	}
+	return nil
+}
+
+// printInspection writes the type, aligned fields and warnings
+func printInspection(w io.Writer, in *encoder.Inspection) {
+	fmt.Fprintf(w, "Type: %s\n", in.Description)
+
+	width := 0
+	for _, f := range in.Fields {
+		width = max(width, len(f.Name))
+	}
+	for _, f := range in.Fields {
+		// Keep multi-line values (notes, bodies) under their field
+		value := strings.ReplaceAll(f.Value, "\n", "\n"+strings.Repeat(" ", width+4))
+		fmt.Fprintf(w, "  %-*s  %s\n", width+1, f.Name+":", value)
+	}
+
+	for _, warning := range in.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+}
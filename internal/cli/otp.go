@@ -29,12 +29,15 @@ Authy, Microsoft Authenticator, and other TOTP/HOTP apps.
 Examples:
   mkqr otp -s "JBSWY3DPEHPK3PXP" -i "GitHub" -a "user@example.com"
   mkqr otp --secret "ABCD1234" --issuer "AWS" --account "myaccount"
-  mkqr otp -s "SECRET" -i "Service" -a "user" --digits 8 --period 60`,
+  mkqr otp -s "SECRET" -i "Service" -a "user" --digits 8 --period 60
+  mkqr otp -s - -i "GitHub" -a "user@example.com"            # prompt for the secret
+  mkqr otp --secret-file github.key -i "GitHub" -a "user@example.com"`,
 	RunE: runOTP,
 }
 
 func init() {
-	otpCmd.Flags().StringVarP(&otpSecret, "secret", "s", "", "Secret key (base32 encoded) [required]")
+	otpCmd.Flags().StringVarP(&otpSecret, "secret", "s", "", "Secret key (base32 encoded, '-' to prompt) [required]")
+	addSecretFlags(otpCmd, "secret")
 	otpCmd.Flags().StringVarP(&otpIssuer, "issuer", "i", "", "Service/issuer name [required]")
 	otpCmd.Flags().StringVarP(&otpAccount, "account", "a", "", "Account name/email [required]")
 	otpCmd.Flags().StringVar(&otpAlgorithm, "algorithm", "SHA1", "Hash algorithm (SHA1/SHA256/SHA512)")
@@ -43,7 +46,6 @@ func init() {
 	otpCmd.Flags().IntVar(&otpCounter, "counter", 0, "Initial counter value (HOTP)")
 	otpCmd.Flags().BoolVar(&otpTypeHOTP, "hotp", false, "Use HOTP (counter-based) instead of TOTP")
 
-	otpCmd.MarkFlagRequired("secret")
 	otpCmd.MarkFlagRequired("issuer")
 	otpCmd.MarkFlagRequired("account")
 
@@ -51,6 +53,10 @@ func init() {
 }
 
 func runOTP(cmd *cobra.Command, args []string) error {
+	if err := resolveSecret(cmd, "secret", &otpSecret, true); err != nil {
+		return err
+	}
+
 	// Validate secret is valid base32
 	if err := encoder.ValidateSecret(otpSecret); err != nil {
 		return fmt.Errorf("invalid secret: %w", err)
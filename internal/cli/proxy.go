@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Lynthar/mkQR/internal/encoder"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Shared by all protocols
+	proxyServer string
+	proxyPort   int
+	proxyName   string
+
+	// Credentials
+	proxyID       string
+	proxyAlterID  int
+	proxyCipher   string
+	proxyFlow     string
+	proxyPassword string
+	proxyMethod   string
+	proxyPlugin   string
+
+	// Transport and TLS
+	proxyNetwork     string
+	proxyHeaderType  string
+	proxyHost        string
+	proxyPath        string
+	proxyServiceName string
+	proxySecurity    string
+	proxySNI         string
+	proxyALPN        string
+	proxyFingerprint string
+	proxyInsecure    bool
+	proxyPublicKey   string
+	proxyShortID     string
+
+	// Hysteria2 and TUIC
+	proxyObfs              string
+	proxyObfsPassword      string
+	proxyPinSHA256         string
+	proxyCongestionControl string
+	proxyUDPRelayMode      string
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Generate QR code for a proxy server share link",
+	Long: `Generate a QR code for a proxy server, building the share link from
+fields instead of assembling it by hand.
+
+Supported protocols:
+  vmess      - v2rayN style base64 JSON
+  vless      - vless:// URI (including REALITY)
+  trojan     - trojan:// URI
+  ss         - Shadowsocks SIP002 URI
+  hysteria2  - hysteria2:// URI
+  tuic       - TUIC v5 URI
+
+Examples:
+  mkqr proxy vmess -s example.com -p 443 --id UUID --network ws --path /ray --security tls
+  mkqr proxy vless -s example.com -p 443 --id UUID --flow xtls-rprx-vision --security reality --sni www.microsoft.com --pbk KEY --sid 0123abcd
+  mkqr proxy trojan -s example.com -p 443 --password secret -n "US 01"
+  mkqr proxy ss -s 1.2.3.4 -p 8388 --method aes-256-gcm --password secret
+  mkqr proxy hysteria2 -s example.com -p 443 --password secret --obfs salamander --obfs-password pw
+  mkqr proxy tuic -s example.com -p 443 --id UUID --password secret --alpn h3`,
+}
+
+var proxyVMessCmd = &cobra.Command{
+	Use:   "vmess",
+	Short: "VMess share link (base64 JSON)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "id", &proxyID, true); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.VMess{
+			Name:      proxyName,
+			Address:   proxyServer,
+			Port:      proxyPort,
+			ID:        proxyID,
+			AlterID:   proxyAlterID,
+			Cipher:    proxyCipher,
+			Transport: proxyTransport(),
+		})
+	},
+}
+
+var proxyVLESSCmd = &cobra.Command{
+	Use:   "vless",
+	Short: "VLESS share link",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "id", &proxyID, true); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.VLESS{
+			Name:      proxyName,
+			Address:   proxyServer,
+			Port:      proxyPort,
+			UUID:      proxyID,
+			Flow:      proxyFlow,
+			Transport: proxyTransport(),
+		})
+	},
+}
+
+var proxyTrojanCmd = &cobra.Command{
+	Use:   "trojan",
+	Short: "Trojan share link",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "password", &proxyPassword, true); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.Trojan{
+			Name:      proxyName,
+			Address:   proxyServer,
+			Port:      proxyPort,
+			Password:  proxyPassword,
+			Transport: proxyTransport(),
+		})
+	},
+}
+
+var proxySSCmd = &cobra.Command{
+	Use:     "ss",
+	Aliases: []string{"shadowsocks"},
+	Short:   "Shadowsocks SIP002 share link",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "password", &proxyPassword, true); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.Shadowsocks{
+			Name:     proxyName,
+			Address:  proxyServer,
+			Port:     proxyPort,
+			Method:   proxyMethod,
+			Password: proxyPassword,
+			Plugin:   proxyPlugin,
+		})
+	},
+}
+
+var proxyHysteria2Cmd = &cobra.Command{
+	Use:     "hysteria2",
+	Aliases: []string{"hy2"},
+	Short:   "Hysteria 2 share link",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "password", &proxyPassword, false); err != nil {
+			return err
+		}
+		if err := resolveSecret(cmd, "obfs-password", &proxyObfsPassword, false); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.Hysteria2{
+			Name:         proxyName,
+			Address:      proxyServer,
+			Port:         proxyPort,
+			Password:     proxyPassword,
+			SNI:          proxySNI,
+			Insecure:     proxyInsecure,
+			Obfs:         proxyObfs,
+			ObfsPassword: proxyObfsPassword,
+			PinSHA256:    proxyPinSHA256,
+		})
+	},
+}
+
+var proxyTUICCmd = &cobra.Command{
+	Use:   "tuic",
+	Short: "TUIC v5 share link",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveSecret(cmd, "id", &proxyID, true); err != nil {
+			return err
+		}
+		if err := resolveSecret(cmd, "password", &proxyPassword, true); err != nil {
+			return err
+		}
+		return generateProxy(cmd, &encoder.TUIC{
+			Name:              proxyName,
+			Address:           proxyServer,
+			Port:              proxyPort,
+			UUID:              proxyID,
+			Password:          proxyPassword,
+			CongestionControl: proxyCongestionControl,
+			UDPRelayMode:      proxyUDPRelayMode,
+			ALPN:              proxyALPN,
+			SNI:               proxySNI,
+			AllowInsecure:     proxyInsecure,
+		})
+	},
+}
+
+func init() {
+	proxyCmd.PersistentFlags().StringVarP(&proxyServer, "server", "s", "", "Server address (hostname or IP) [required]")
+	proxyCmd.PersistentFlags().IntVarP(&proxyPort, "port", "p", 0, "Server port [required]")
+	proxyCmd.PersistentFlags().StringVarP(&proxyName, "name", "n", "", "Display name shown in the client")
+	proxyCmd.MarkPersistentFlagRequired("server")
+	proxyCmd.MarkPersistentFlagRequired("port")
+
+	proxyVMessCmd.Flags().StringVar(&proxyID, "id", "", "User ID (UUID) ('-' to prompt) [required]")
+	addSecretFlags(proxyVMessCmd, "id")
+	proxyVMessCmd.Flags().IntVar(&proxyAlterID, "alter-id", 0, "Alter ID (0 for AEAD)")
+	proxyVMessCmd.Flags().StringVar(&proxyCipher, "cipher", "auto", "Cipher (auto/aes-128-gcm/chacha20-poly1305/none/zero)")
+	addTransportFlags(proxyVMessCmd)
+
+	proxyVLESSCmd.Flags().StringVar(&proxyID, "id", "", "User ID (UUID) ('-' to prompt) [required]")
+	addSecretFlags(proxyVLESSCmd, "id")
+	proxyVLESSCmd.Flags().StringVar(&proxyFlow, "flow", "", "Flow control (e.g. xtls-rprx-vision)")
+	addTransportFlags(proxyVLESSCmd)
+
+	proxyTrojanCmd.Flags().StringVar(&proxyPassword, "password", "", "Password ('-' to prompt) [required]")
+	addSecretFlags(proxyTrojanCmd, "password")
+	addTransportFlags(proxyTrojanCmd)
+
+	proxySSCmd.Flags().StringVar(&proxyMethod, "method", "", "Cipher (e.g. aes-256-gcm, chacha20-ietf-poly1305, 2022-blake3-aes-128-gcm) [required]")
+	proxySSCmd.Flags().StringVar(&proxyPassword, "password", "", "Password ('-' to prompt) [required]")
+	addSecretFlags(proxySSCmd, "password")
+	proxySSCmd.Flags().StringVar(&proxyPlugin, "plugin", "", `Plugin and options (e.g. "obfs-local;obfs=http;obfs-host=example.com")`)
+	proxySSCmd.MarkFlagRequired("method")
+
+	proxyHysteria2Cmd.Flags().StringVar(&proxyPassword, "password", "", "Authentication password ('-' to prompt)")
+	addSecretFlags(proxyHysteria2Cmd, "password")
+	proxyHysteria2Cmd.Flags().StringVar(&proxySNI, "sni", "", "TLS server name")
+	proxyHysteria2Cmd.Flags().BoolVar(&proxyInsecure, "insecure", false, "Skip certificate verification")
+	proxyHysteria2Cmd.Flags().StringVar(&proxyObfs, "obfs", "", "Obfuscation type (salamander)")
+	proxyHysteria2Cmd.Flags().StringVar(&proxyObfsPassword, "obfs-password", "", "Obfuscation password ('-' to prompt)")
+	addSecretFlags(proxyHysteria2Cmd, "obfs-password")
+	proxyHysteria2Cmd.Flags().StringVar(&proxyPinSHA256, "pin-sha256", "", "SHA-256 fingerprint of the server certificate to pin")
+
+	proxyTUICCmd.Flags().StringVar(&proxyID, "id", "", "User ID (UUID) ('-' to prompt) [required]")
+	addSecretFlags(proxyTUICCmd, "id")
+	proxyTUICCmd.Flags().StringVar(&proxyPassword, "password", "", "Password ('-' to prompt) [required]")
+	addSecretFlags(proxyTUICCmd, "password")
+	proxyTUICCmd.Flags().StringVar(&proxyCongestionControl, "congestion-control", "", "Congestion control (bbr/cubic/new_reno)")
+	proxyTUICCmd.Flags().StringVar(&proxyUDPRelayMode, "udp-relay-mode", "", "UDP relay mode (native/quic)")
+	proxyTUICCmd.Flags().StringVar(&proxyALPN, "alpn", "", "TLS ALPN, comma-separated (e.g. h3)")
+	proxyTUICCmd.Flags().StringVar(&proxySNI, "sni", "", "TLS server name")
+	proxyTUICCmd.Flags().BoolVar(&proxyInsecure, "insecure", false, "Skip certificate verification")
+
+	proxyCmd.AddCommand(proxyVMessCmd, proxyVLESSCmd, proxyTrojanCmd, proxySSCmd, proxyHysteria2Cmd, proxyTUICCmd)
+	rootCmd.AddCommand(proxyCmd)
+}
+
+// addTransportFlags adds the transport and TLS flags shared by VMess,
+// VLESS and Trojan
+func addTransportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&proxyNetwork, "network", "tcp", "Transport (tcp/ws/grpc/h2/httpupgrade/xhttp/kcp/quic)")
+	cmd.Flags().StringVar(&proxyHeaderType, "header-type", "", "Header obfuscation for tcp/kcp/quic (e.g. http)")
+	cmd.Flags().StringVar(&proxyHost, "host", "", "HTTP Host header")
+	cmd.Flags().StringVar(&proxyPath, "path", "", "Path for ws/h2/httpupgrade/xhttp")
+	cmd.Flags().StringVar(&proxyServiceName, "service-name", "", "gRPC service name")
+	cmd.Flags().StringVar(&proxySecurity, "security", "", "Transport security (none/tls/reality)")
+	cmd.Flags().StringVar(&proxySNI, "sni", "", "TLS server name")
+	cmd.Flags().StringVar(&proxyALPN, "alpn", "", "TLS ALPN, comma-separated (e.g. h2,http/1.1)")
+	cmd.Flags().StringVar(&proxyFingerprint, "fp", "", "uTLS fingerprint (e.g. chrome, firefox)")
+	cmd.Flags().BoolVar(&proxyInsecure, "insecure", false, "Skip certificate verification")
+	cmd.Flags().StringVar(&proxyPublicKey, "pbk", "", "REALITY public key")
+	cmd.Flags().StringVar(&proxyShortID, "sid", "", "REALITY short ID")
+}
+
+func proxyTransport() encoder.ProxyTransport {
+	return encoder.ProxyTransport{
+		Network:       proxyNetwork,
+		HeaderType:    proxyHeaderType,
+		Host:          proxyHost,
+		Path:          proxyPath,
+		ServiceName:   proxyServiceName,
+		Security:      proxySecurity,
+		SNI:           proxySNI,
+		ALPN:          proxyALPN,
+		Fingerprint:   proxyFingerprint,
+		AllowInsecure: proxyInsecure,
+		PublicKey:     proxyPublicKey,
+		ShortID:       proxyShortID,
+	}
+}
+
+// generateProxy checks the fields common to all protocols and renders the
+// share link
+func generateProxy(cmd *cobra.Command, link encoder.Encoder) error {
+	if proxyPort < 1 || proxyPort > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", proxyPort)
+	}
+	if proxySecurity == "reality" && proxyPublicKey == "" {
+		return fmt.Errorf("--pbk is required with --security reality")
+	}
+
+	content := link.Encode()
+	_, problems := encoder.Detect(content)
+	if err := checkProxy(problems); err != nil {
+		return err
+	}
+
+	if !quiet {
+		name := proxyName
+		if name == "" {
+			name = proxyServer
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Proxy: %s %s (%s:%d)\n", cmd.Name(), name, proxyServer, proxyPort)
+	}
+
+	return generateQR(content)
+}
@@ -2,25 +2,52 @@ package cli
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/Lynthar/mkQR/internal/encoder"
 	"github.com/Lynthar/mkQR/internal/qr"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
 	// Global flags
-	outputFile  string
-	outputSize  int
-	errorLevel  string
-	quiet       bool
-	invert      bool
-	small       bool
-	showVersion bool
+	outputFile   string
+	outputFormat string
+	outputSize   int
+	errorLevel   string
+	quiet        bool
+	invert       bool
+	small        bool
+	showVersion  bool
+	fgColor      string
+	bgColor      string
+	logoFile     string
+	logoScale    float64
+	moduleStyle  string
+	eyeStyle     string
+	margin       int
+	moduleSize   int
+	verify       bool
+	qrVersion    int
+	qrMask       int
+	eciCharset   string
+	symbolType   string
+	marginFlag   *pflag.Flag // Tells a chosen --margin from the default
+	lint         bool
+
+	// PDF output flags
+	pdfPage      string
+	pdfMargin    float64
+	pdfWidth     float64
+	pdfDPI       float64
+	pdfCaption   string
+	pdfPlacement string
 
 	// Version info (set at build time)
 	Version   = "dev"
@@ -35,13 +62,23 @@ var rootCmd = &cobra.Command{
 	Long: `mkQR - A fast, flexible QR code generator for the command line.
 
 Supports WiFi, URLs, contacts, OTP, and many more formats.
-Can output to terminal, PNG, or base64.
+Can output to terminal, PNG, SVG, PDF, or base64.
 
 Examples:
   mkqr "Hello World"                    # Generate QR for text
   mkqr "https://github.com"             # Auto-detect URL
   mkqr wifi -s "MyNetwork" -p "pass"    # WiFi network
   mkqr "vmess://..." -o proxy.png       # Save proxy QR to file
+  mkqr url example.com -o flyer.pdf --page Letter --caption "Visit us"
+  mkqr "text" --format data-uri         # Print data:image/png;base64,...
+  mkqr "text" -o qr.png --fg navy --bg transparent
+  mkqr url example.com -o qr.png --logo brand.png
+  mkqr url example.com -o qr.svg --style dot --eye-style rounded
+  mkqr "text" -o label.png --module-size 4 --margin 2
+  mkqr "text" -o fixed.png --qr-version 5 --mask 2
+  mkqr "张伟 13800138000" --eci gb18030 -o card.png
+  mkqr "tel:+15551234" --symbol micro -o tag.png
+  mkqr wifi -s "Cafe" -p "p@ss;word" --verify -o wifi.png
   echo "text" | mkqr                    # Read from stdin`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runRoot,
@@ -49,13 +86,37 @@ Examples:
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Output file (PNG format)")
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Output file (format from extension: .png, .svg, .pdf)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "", "Output format: terminal, png, svg, pdf, base64, data-uri (default: from -o extension, else terminal)")
 	rootCmd.PersistentFlags().IntVar(&outputSize, "size", 256, "QR code size in pixels")
+	rootCmd.PersistentFlags().IntVar(&moduleSize, "module-size", 0, "Exact pixels per module for raster output (overrides --size)")
 	rootCmd.PersistentFlags().StringVarP(&errorLevel, "level", "l", "M", "Error correction level (L/M/Q/H)")
+	rootCmd.PersistentFlags().IntVar(&qrVersion, "qr-version", 0, "Symbol version 1-40 (default: smallest that fits)")
+	rootCmd.PersistentFlags().IntVar(&qrMask, "mask", qr.AutoMask, "Mask pattern 0-7 (default: lowest penalty)")
+	rootCmd.PersistentFlags().StringVar(&symbolType, "symbol", "qr", "Symbol type: qr, or micro for a Micro QR code (M1-M4) on small labels")
+	rootCmd.PersistentFlags().StringVar(&eciCharset, "eci", "auto", "Character set announced by an ECI header (auto/utf-8/iso-8859-1/shift-jis/gb18030)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress non-essential output")
+	rootCmd.PersistentFlags().BoolVar(&verify, "verify", false, "Decode each generated code and fail if it does not read back as the input")
+	rootCmd.PersistentFlags().BoolVar(&lint, "lint", false, "Warn about invalid proxy links instead of refusing them")
+	rootCmd.PersistentFlags().StringVar(&fgColor, "fg", "", "Foreground color (#RRGGBB, #RRGGBBAA, or name) (default black)")
+	rootCmd.PersistentFlags().StringVar(&bgColor, "bg", "", "Background color (#RRGGBB, #RRGGBBAA, name, or transparent) (default white)")
+	rootCmd.PersistentFlags().StringVar(&logoFile, "logo", "", "Image (PNG/JPEG/GIF) to place in the centre of PNG/SVG output")
+	rootCmd.PersistentFlags().Float64Var(&logoScale, "logo-scale", qr.DefaultLogoScale, "Logo width as a fraction of the code width")
+	rootCmd.PersistentFlags().IntVar(&margin, "margin", qr.DefaultMargin, "Quiet zone width in modules (all outputs; Micro QR defaults to 2)")
+	marginFlag = rootCmd.PersistentFlags().Lookup("margin")
+	rootCmd.PersistentFlags().StringVar(&moduleStyle, "style", "square", "Module shape for PNG/SVG output (square/dot/rounded/connected)")
+	rootCmd.PersistentFlags().StringVar(&eyeStyle, "eye-style", "square", "Finder pattern shape for PNG/SVG output (square/rounded/circle)")
 	rootCmd.PersistentFlags().BoolVar(&invert, "invert", false, "Invert colors (for dark terminals)")
 	rootCmd.PersistentFlags().BoolVar(&small, "small", false, "Use compact display mode")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
+
+	// PDF output flags
+	rootCmd.PersistentFlags().StringVar(&pdfPage, "page", "A4", "PDF page size (A3/A4/A5/A6/Letter/Legal or WIDTHxHEIGHT in mm)")
+	rootCmd.PersistentFlags().Float64Var(&pdfMargin, "page-margin", 15, "PDF page margin in mm")
+	rootCmd.PersistentFlags().Float64Var(&pdfWidth, "print-width", 0, "PDF QR code width in mm (default: fit page)")
+	rootCmd.PersistentFlags().Float64Var(&pdfDPI, "dpi", 0, "PDF print resolution; sizes the code as --size pixels at this DPI")
+	rootCmd.PersistentFlags().StringVar(&pdfCaption, "caption", "", "PDF caption printed below the code")
+	rootCmd.PersistentFlags().StringVar(&pdfPlacement, "placement", "center", "PDF placement on the page (center/top)")
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
@@ -92,11 +153,15 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	}
 
 	// Auto-detect content type
-	contentType, description := encoder.DetectAndDescribe(content)
+	contentType, description, problems := encoder.DetectAndDescribe(content)
 	if !quiet {
 		fmt.Fprintf(os.Stderr, "Detected: %s\n", description)
 	}
 
+	if err := checkProxy(problems); err != nil {
+		return err
+	}
+
 	// For detected URLs without protocol, add https://
 	if contentType == encoder.TypeURL && !strings.HasPrefix(strings.ToLower(content), "http") {
 		content = "https://" + content
@@ -105,21 +170,33 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	return generateQR(content)
 }
 
+// checkProxy turns the problems Detect found in a proxy share link into
+// an error, or warnings on stderr with --lint
+func checkProxy(err error) error {
+	if err == nil {
+		return nil
+	}
+	if !lint {
+		return fmt.Errorf("invalid proxy link (use --lint to generate anyway):\n%w", err)
+	}
+	for _, problem := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", problem)
+	}
+	return nil
+}
+
 // generateQR is the common QR generation logic
 func generateQR(content string) error {
-	// Validate size
-	if outputSize <= 0 {
-		return fmt.Errorf("size must be a positive number, got %d", outputSize)
+	opts, err := buildOptions()
+	if err != nil {
+		return err
 	}
-
-	level, err := qr.ParseLevel(errorLevel)
+	format, err := resolveFormat()
 	if err != nil {
 		return err
 	}
-
-	opts := qr.Options{
-		Level: level,
-		Size:  outputSize,
+	if err := checkLogoFormat(format); err != nil {
+		return err
 	}
 
 	gen := qr.NewGenerator(opts)
@@ -128,26 +205,276 @@ func generateQR(content string) error {
 		return err
 	}
 
-	// Output to file or terminal
+	if opts.Logo != nil && qrCode.Level != opts.Level && !quiet {
+		fmt.Fprintf(os.Stderr, "Logo: raising error correction level to %s\n", qrCode.Level)
+	}
+
+	switch {
+	case quiet:
+	case qrCode.Micro && qrCode.Version == 1:
+		fmt.Fprintf(os.Stderr, "Symbol: M1, error detection only, mask %d\n", qrCode.Mask)
+	case qrCode.Micro:
+		fmt.Fprintf(os.Stderr, "Symbol: M%d-%s, mask %d\n", qrCode.Version, qrCode.Level, qrCode.Mask)
+	case qrVersion != 0 || qrMask != qr.AutoMask:
+		fmt.Fprintf(os.Stderr, "Symbol: version %d-%s, mask %d\n", qrCode.Version, qrCode.Level, qrCode.Mask)
+	}
+
+	// Report when mixed-mode segmentation made the symbol smaller
+	if qrVersion == 0 && !qrCode.Micro && !quiet {
+		switch byteVersion := qrCode.ByteModeVersion; {
+		case byteVersion == 0:
+			fmt.Fprintf(os.Stderr, "Segmentation: fits version %d, too long for byte mode\n", qrCode.Version)
+		case byteVersion > qrCode.Version:
+			fmt.Fprintf(os.Stderr, "Segmentation: version %d instead of %d in byte mode\n", qrCode.Version, byteVersion)
+		}
+	}
+
+	if verify {
+		if err := qr.Verify(qrCode, content, outputSize); err != nil {
+			return err
+		}
+		if !quiet {
+			fmt.Fprintln(os.Stderr, "Verified: code reads back as the input")
+		}
+	}
+
+	// Report the exact raster dimensions when sizing by module
+	if moduleSize > 0 && !quiet && (format == qr.FormatPNG || format == qr.FormatBase64 || format == qr.FormatDataURI) {
+		px := qrCode.PixelSize(outputSize)
+		fmt.Fprintf(os.Stderr, "Image: %dx%d px (%d px per module)\n", px, px, moduleSize)
+	}
+
+	// Output to file or stdout
 	if outputFile != "" {
-		if err := qr.SavePNG(qrCode, outputFile, outputSize); err != nil {
+		if err := saveQR(qrCode, outputFile, format); err != nil {
 			return err
 		}
 		if !quiet {
 			fmt.Fprintf(os.Stderr, "Saved to: %s\n", outputFile)
 		}
-	} else {
-		// Render to terminal
+		return nil
+	}
+
+	return writeQR(os.Stdout, qrCode, format)
+}
+
+// buildOptions validates the global generation flags and converts them
+// into generator options
+func buildOptions() (qr.Options, error) {
+	opts := qr.DefaultOptions()
+
+	// Validate size
+	if outputSize <= 0 {
+		return opts, fmt.Errorf("size must be a positive number, got %d", outputSize)
+	}
+	opts.Size = outputSize
+
+	if moduleSize < 0 {
+		return opts, fmt.Errorf("module size must be a positive number, got %d", moduleSize)
+	}
+	opts.ModuleSize = moduleSize
+
+	switch strings.ToLower(symbolType) {
+	case "qr":
+	case "micro":
+		opts.Micro = true
+	default:
+		return opts, fmt.Errorf("invalid symbol type: %s (use qr or micro)", symbolType)
+	}
+	if opts.Micro && logoFile != "" {
+		return opts, fmt.Errorf("--logo cannot be used with --symbol micro: a logo does not fit on a Micro QR symbol")
+	}
+
+	maxVersion, maxMask := 40, 7
+	if opts.Micro {
+		maxVersion, maxMask = 4, 3
+	}
+	if qrVersion < 0 || qrVersion > maxVersion {
+		return opts, fmt.Errorf("QR version must be between 1 and %d, got %d", maxVersion, qrVersion)
+	}
+	opts.Version = qrVersion
+	if qrMask < qr.AutoMask || qrMask > maxMask {
+		return opts, fmt.Errorf("mask must be between 0 and %d, got %d", maxMask, qrMask)
+	}
+	opts.Mask = qrMask
+
+	level, err := qr.ParseLevel(errorLevel)
+	if err != nil {
+		return opts, err
+	}
+	opts.Level = level
+
+	if opts.ECI, err = qr.ParseCharset(eciCharset); err != nil {
+		return opts, err
+	}
+	// Set by mkqr text only
+	if opts.Mode, err = qr.ParseMode(textMode); err != nil {
+		return opts, err
+	}
+
+	if fgColor != "" {
+		if opts.ForegroundColor, err = qr.ParseColor(fgColor); err != nil {
+			return opts, err
+		}
+	}
+	if bgColor != "" {
+		if opts.BackgroundColor, err = qr.ParseColor(bgColor); err != nil {
+			return opts, err
+		}
+	}
+
+	// Micro QR needs only a 2-module quiet zone, the default unless set
+	quietZone, m := qr.DefaultMargin, margin
+	if opts.Micro {
+		quietZone = qr.MicroMargin
+		if !marginFlag.Changed {
+			m = qr.MicroMargin
+		}
+	}
+	if m < 0 {
+		return opts, fmt.Errorf("margin cannot be negative, got %d", m)
+	}
+	if m < quietZone && !quiet {
+		fmt.Fprintf(os.Stderr, "Warning: margin %d is below the %d-module quiet zone required by the QR specification, some scanners may fail\n", m, quietZone)
+	}
+	opts.Margin = m
+
+	if opts.ModuleStyle, err = qr.ParseModuleStyle(moduleStyle); err != nil {
+		return opts, err
+	}
+	if opts.EyeStyle, err = qr.ParseEyeStyle(eyeStyle); err != nil {
+		return opts, err
+	}
+
+	if logoFile != "" {
+		if opts.Logo, err = qr.LoadLogo(logoFile); err != nil {
+			return opts, err
+		}
+		opts.LogoScale = logoScale
+
+		// Generate picks the level, reported once the code is built
+		if _, err := qr.LogoLevel(opts.Level, opts.LogoScale); err != nil {
+			return opts, err
+		}
+	}
+
+	warning, err := qr.CheckContrast(opts.ForegroundColor, opts.BackgroundColor)
+	if err != nil {
+		return opts, err
+	}
+	if warning != "" && !quiet {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	return opts, nil
+}
+
+// resolveFormat picks the output format from --format, falling back to
+// the output file extension, or the terminal when writing to stdout
+func resolveFormat() (qr.OutputFormat, error) {
+	if outputFormat != "" {
+		return qr.ParseFormat(outputFormat)
+	}
+	if outputFile != "" {
+		return qr.DetectFormat(outputFile), nil
+	}
+	return qr.FormatTerminal, nil
+}
+
+// checkLogoFormat rejects --logo for output formats that cannot draw it,
+// before the logo raises the error correction level for nothing
+func checkLogoFormat(format qr.OutputFormat) error {
+	if logoFile != "" && (format == qr.FormatTerminal || format == qr.FormatPDF) {
+		return fmt.Errorf("--logo cannot be used with %s output: the logo is only drawn on PNG and SVG output", format)
+	}
+	return nil
+}
+
+// writeQR writes the QR code to w in the given format
+func writeQR(w io.Writer, qrCode *qr.Code, format qr.OutputFormat) error {
+	switch format {
+	case qr.FormatTerminal:
 		cfg := qr.TerminalConfig{
 			Invert: invert,
 			Small:  small,
 		}
-		qr.RenderTerminal(os.Stdout, qrCode, cfg)
+		qr.RenderTerminal(w, qrCode, cfg)
+		return nil
+	case qr.FormatSVG:
+		return qr.WriteSVG(w, qrCode, outputSize)
+	case qr.FormatPDF:
+		cfg, err := pdfConfig(qrCode)
+		if err != nil {
+			return err
+		}
+		return qr.WritePDF(w, qrCode, cfg)
+	case qr.FormatBase64:
+		encoded, err := qr.ToBase64(qrCode, outputSize)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, encoded)
+		return err
+	case qr.FormatDataURI:
+		uri, err := qr.ToDataURI(qrCode, outputSize)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, uri)
+		return err
+	default:
+		return qr.WritePNG(w, qrCode, outputSize)
+	}
+}
+
+// saveQR writes the QR code to filename in the given format. The output
+// is rendered first, so a failure leaves any existing file untouched.
+func saveQR(qrCode *qr.Code, filename string, format qr.OutputFormat) error {
+	var buf bytes.Buffer
+	if err := writeQR(&buf, qrCode, format); err != nil {
+		return err
 	}
 
+	if dir := filepath.Dir(filename); dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
 	return nil
 }
 
+// pdfConfig builds the PDF layout for qrCode from the PDF output flags
+func pdfConfig(qrCode *qr.Code) (qr.PDFConfig, error) {
+	cfg := qr.DefaultPDFConfig()
+
+	width, height, err := qr.ParsePageSize(pdfPage)
+	if err != nil {
+		return cfg, err
+	}
+	placement, err := qr.ParsePlacement(pdfPlacement)
+	if err != nil {
+		return cfg, err
+	}
+	if pdfDPI < 0 {
+		return cfg, fmt.Errorf("dpi must be a positive number, got %g", pdfDPI)
+	}
+
+	cfg.PageWidth = width
+	cfg.PageHeight = height
+	cfg.Margin = pdfMargin
+	cfg.Placement = placement
+	cfg.Caption = pdfCaption
+	cfg.Width = pdfWidth
+	if cfg.Width == 0 && pdfDPI > 0 {
+		cfg.Width = float64(qrCode.PixelSize(outputSize)) / pdfDPI * 25.4
+	}
+
+	return cfg, nil
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// addSecretFlags adds --<name>-file and --<name>-env as alternatives to
+// the secret flag name, so the secret need not appear in shell history or
+// the process list
+func addSecretFlags(cmd *cobra.Command, name string) {
+	cmd.Flags().String(name+"-file", "", fmt.Sprintf("Read the %s from a file", name))
+	cmd.Flags().String(name+"-env", "", fmt.Sprintf("Read the %s from an environment variable", name))
+}
+
+// secretGiven reports whether any source of the secret flag name was used
+func secretGiven(cmd *cobra.Command, name string) bool {
+	flags := cmd.Flags()
+	return flags.Changed(name) || flags.Changed(name+"-file") || flags.Changed(name+"-env")
+}
+
+// resolveSecret sets value from the source the user chose for the secret
+// flag name: the flag itself, where "-" prompts without echo (or reads a
+// line from piped stdin), a file, or an environment variable
+func resolveSecret(cmd *cobra.Command, name string, value *string, required bool) error {
+	flags := cmd.Flags()
+	sources := 0
+	for _, flag := range []string{name, name + "-file", name + "-env"} {
+		if flags.Changed(flag) {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("use only one of --%s, --%s-file and --%s-env", name, name, name)
+	}
+
+	file, _ := flags.GetString(name + "-file")
+	env, _ := flags.GetString(name + "-env")
+	switch {
+	case flags.Changed(name + "-file"):
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s file: %w", name, err)
+		}
+		// Editors and echo add a trailing newline that is not part of the secret
+		*value = strings.TrimRight(string(data), "\r\n")
+		if *value == "" {
+			return fmt.Errorf("%s file %s is empty", name, file)
+		}
+	case flags.Changed(name + "-env"):
+		secret, ok := os.LookupEnv(env)
+		if !ok || secret == "" {
+			return fmt.Errorf("environment variable %s is not set", env)
+		}
+		*value = secret
+	case *value == "-":
+		secret, err := promptSecret(cmd, name)
+		if err != nil {
+			return err
+		}
+		*value = secret
+	}
+
+	if required && *value == "" {
+		return fmt.Errorf("--%s, --%s-file or --%s-env is required", name, name, name)
+	}
+	return nil
+}
+
+// promptSecret asks for a secret on the terminal without echoing it, or
+// reads the first line of stdin when it is not a terminal
+func promptSecret(cmd *cobra.Command, name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s from stdin: %w", name, err)
+			}
+			return "", fmt.Errorf("no %s on stdin", name)
+		}
+		return line, nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s: ", strings.ToUpper(name[:1])+name[1:])
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(secret) == 0 {
+		return "", fmt.Errorf("no %s entered", name)
+	}
+	return string(secret), nil
+}
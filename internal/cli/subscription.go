@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Lynthar/mkQR/internal/encoder"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subOutputDir string
+	subPrefix    string
+)
+
+var subscriptionCmd = &cobra.Command{
+	Use:     "subscription <file>",
+	Aliases: []string{"sub"},
+	Short:   "Generate QR codes for every node of a proxy subscription",
+	Long: `Generate a QR code for each proxy server of a subscription.
+
+Accepts a Clash (Mihomo) YAML config, a sing-box JSON config, or a list
+of share links, plain or base64 encoded as subscription servers deliver
+them. Each node is converted to its share link (vmess, vless, trojan, ss,
+hysteria2, tuic) and saved like batch output, named after the node.
+Nodes of other types are skipped with a message.
+
+Examples:
+  mkqr subscription clash.yaml -O ./nodes/
+  mkqr sub config.json -O ./nodes/ --format svg
+  curl -s https://example.com/sub | mkqr sub - -O ./nodes/ --prefix "hk_"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSubscription,
+}
+
+func init() {
+	subscriptionCmd.Flags().StringVarP(&subOutputDir, "output-dir", "O", ".", "Output directory")
+	subscriptionCmd.Flags().StringVar(&subPrefix, "prefix", "", "Filename prefix")
+
+	rootCmd.AddCommand(subscriptionCmd)
+}
+
+func runSubscription(cmd *cobra.Command, args []string) error {
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open subscription: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read subscription: %w", err)
+	}
+
+	nodes, skipped, err := encoder.ParseSubscription(data)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		for _, s := range skipped {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s\n", s)
+		}
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes could be converted to share links")
+	}
+
+	items := make([]batchItem, len(nodes))
+	for i, node := range nodes {
+		source := fmt.Sprintf("node %d", i+1)
+		if node.Name != "" {
+			source = fmt.Sprintf("node %q", node.Name)
+		}
+		items[i] = batchItem{content: node.Link, source: source, name: node.Name}
+	}
+
+	return renderBatch(cmd, items, subOutputDir, subPrefix)
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var textMode string
+
 var textCmd = &cobra.Command{
 	Use:   "text <content>",
 	Short: "Generate QR code for plain text",
@@ -17,12 +19,14 @@ without any automatic URL or format detection.
 Examples:
   mkqr text "Hello World"
   mkqr text "This is a multi-line\ntext message"
-  mkqr text "Some text that looks like a URL: example.com"`,
+  mkqr text "Some text that looks like a URL: example.com"
+  mkqr text "日本語のテキスト" --mode kanji`,
 	Args: cobra.ExactArgs(1),
 	RunE: runText,
 }
 
 func init() {
+	textCmd.Flags().StringVar(&textMode, "mode", "auto", "Segment mode: auto (shortest mix) or kanji (Shift-JIS kanji only, for Japanese text)")
 	rootCmd.AddCommand(textCmd)
 }
 
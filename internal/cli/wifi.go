@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/Lynthar/mkQR/internal/encoder"
 	"github.com/spf13/cobra"
@@ -12,6 +15,21 @@ var (
 	wifiPassword   string
 	wifiEncryption string
 	wifiHidden     bool
+
+	wifiTransitionDisable bool
+	wifiEAPMethod         string
+	wifiPhase2            string
+	wifiAnonIdentity      string
+	wifiIdentity          string
+	wifiFrom              string
+
+	wifiDPP        bool
+	wifiDPPKey     string
+	wifiDPPKeygen  string
+	wifiChannels   []string
+	wifiMAC        string
+	wifiInfo       string
+	wifiDPPVersion int
 )
 
 var wifiCmd = &cobra.Command{
@@ -20,30 +38,93 @@ var wifiCmd = &cobra.Command{
 	Long: `Generate a QR code that allows devices to connect to a WiFi network.
 
 Supported encryption types:
-  WPA   - WPA/WPA2/WPA3 (default when password is provided)
-  WEP   - WEP encryption
-  nopass - Open network (no password)
+  WPA      - WPA/WPA2, or WPA2/WPA3 transition mode (default when password is provided)
+  SAE      - WPA3 Personal only (alias WPA3)
+  WPA2-EAP - Enterprise 802.1X (default when --eap-method is given)
+  WEP      - WEP encryption
+  nopass   - Open network (no password)
+
+EAP methods: PEAP, TTLS, TLS, PWD, SIM, AKA, AKA'
+Phase 2 methods (PEAP/TTLS): PAP, MSCHAP, MSCHAPV2, GTC, SIM, AKA, AKA'
 
 Examples:
   mkqr wifi -s "MyNetwork" -p "password123"
   mkqr wifi --ssid "Home WiFi" --password "secret" --encryption WPA
   mkqr wifi -s "Guest" --encryption nopass
-  mkqr wifi -s "Hidden Network" -p "pass" --hidden`,
+  mkqr wifi -s "Hidden Network" -p "pass" --hidden
+  mkqr wifi -s "MyNetwork" -p -                       # prompt for the password
+  mkqr wifi -s "MyNetwork" --password-env WIFI_PASSWORD
+  mkqr wifi -s "Home" -p "password123" -e SAE --transition-disable
+  mkqr wifi -s "Office" -p "secret" --eap-method PEAP --phase2 MSCHAPV2 --identity alice
+
+Importing saved networks:
+  --from reads a NetworkManager keyfile (.nmconnection) or a
+  wpa_supplicant.conf. When the file has several networks, pick one with
+  --ssid. Other flags override what the file says.
+
+  sudo mkqr wifi --from /etc/NetworkManager/system-connections/Office.nmconnection
+  sudo mkqr wifi --from /etc/wpa_supplicant/wpa_supplicant.conf -s "Home"
+
+Wi-Fi Easy Connect (DPP):
+  With --dpp a device bootstrapping URI (DPP:) is generated instead, for
+  headless devices a configurator phone onboards by scanning it. The URI
+  carries the device's P-256 public key: read it from an existing key file
+  with --dpp-key, or create a fresh pair with --dpp-keygen, which writes
+  the private key for the device as SEC 1 PEM. hostapd and wpa_supplicant
+  take it as hex DER instead, in dpp_bootstrap_gen key=<hex>, which
+  --dpp-keygen prints; for an existing key use
+  openssl ec -in device.pem -outform DER | xxd -p -c0
+
+  mkqr wifi --dpp --dpp-keygen device.pem --channel 81/1 --mac 52:54:00:58:28:e5 -o dpp.png
+  mkqr wifi --dpp --dpp-key device.pem --info "kitchen sensor" --dpp-version 2`,
 	RunE: runWifi,
 }
 
 func init() {
-	wifiCmd.Flags().StringVarP(&wifiSSID, "ssid", "s", "", "Network name (SSID) [required]")
-	wifiCmd.Flags().StringVarP(&wifiPassword, "password", "p", "", "Network password")
-	wifiCmd.Flags().StringVarP(&wifiEncryption, "encryption", "e", "", "Encryption type (WPA/WEP/nopass)")
+	wifiCmd.Flags().StringVarP(&wifiSSID, "ssid", "s", "", "Network name (SSID) [required unless --dpp or --from]")
+	wifiCmd.Flags().StringVarP(&wifiPassword, "password", "p", "", "Network password ('-' to prompt)")
+	addSecretFlags(wifiCmd, "password")
+	wifiCmd.Flags().StringVarP(&wifiEncryption, "encryption", "e", "", "Encryption type (WPA/SAE/WPA2-EAP/WEP/nopass)")
 	wifiCmd.Flags().BoolVarP(&wifiHidden, "hidden", "H", false, "Hidden network")
+	wifiCmd.Flags().BoolVar(&wifiTransitionDisable, "transition-disable", false, "Stop WPA3 devices falling back to WPA2 once joined")
+	wifiCmd.Flags().StringVar(&wifiEAPMethod, "eap-method", "", "EAP method for WPA2-EAP (PEAP/TTLS/TLS/PWD/SIM/AKA/AKA')")
+	wifiCmd.Flags().StringVar(&wifiPhase2, "phase2", "", "Phase 2 authentication for PEAP/TTLS (e.g. MSCHAPV2)")
+	wifiCmd.Flags().StringVar(&wifiAnonIdentity, "anonymous-identity", "", "Anonymous outer identity for WPA2-EAP")
+	wifiCmd.Flags().StringVar(&wifiIdentity, "identity", "", "User identity for WPA2-EAP")
 
-	wifiCmd.MarkFlagRequired("ssid")
+	wifiCmd.Flags().StringVar(&wifiFrom, "from", "", "Read the network from a NetworkManager keyfile or wpa_supplicant.conf")
+	wifiCmd.Flags().BoolVar(&wifiDPP, "dpp", false, "Generate a Wi-Fi Easy Connect (DPP) bootstrapping URI")
+	wifiCmd.Flags().StringVar(&wifiDPPKey, "dpp-key", "", "DPP key file (PEM private/public key or base64 public key)")
+	wifiCmd.Flags().StringVar(&wifiDPPKeygen, "dpp-keygen", "", "Generate a new DPP key pair, writing the private key to this file")
+	wifiCmd.Flags().StringSliceVar(&wifiChannels, "channel", nil, "DPP listen channel as operating class/channel, e.g. 81/1 (repeatable)")
+	wifiCmd.Flags().StringVar(&wifiMAC, "mac", "", "DPP device MAC address")
+	wifiCmd.Flags().StringVar(&wifiInfo, "info", "", "DPP device information, e.g. a serial number")
+	wifiCmd.Flags().IntVar(&wifiDPPVersion, "dpp-version", 0, "DPP protocol version to advertise")
 
 	rootCmd.AddCommand(wifiCmd)
 }
 
 func runWifi(cmd *cobra.Command, args []string) error {
+	if wifiDPP {
+		return runDPP(cmd)
+	}
+	if err := resolveSecret(cmd, "password", &wifiPassword, false); err != nil {
+		return err
+	}
+	if wifiFrom != "" {
+		if err := loadWiFiConfig(cmd); err != nil {
+			return err
+		}
+	}
+	if wifiSSID == "" {
+		return fmt.Errorf("--ssid is required")
+	}
+	for _, name := range []string{"dpp-key", "dpp-keygen", "channel", "mac", "info", "dpp-version"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s requires --dpp", name)
+		}
+	}
+
 	encryption := encoder.WPA
 	if wifiEncryption != "" {
 		var err error
@@ -51,22 +132,200 @@ func runWifi(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+	} else if wifiEAPMethod != "" {
+		encryption = encoder.WPA2EAP
 	} else if wifiPassword == "" {
 		encryption = encoder.NoPass
 	}
 
+	eapMethod := strings.ToUpper(wifiEAPMethod)
+	phase2 := strings.ToUpper(wifiPhase2)
+	if encryption == encoder.WPA2EAP {
+		if eapMethod == "" {
+			return fmt.Errorf("--eap-method is required for WPA2-EAP networks")
+		}
+		if err := encoder.ValidateEAP(eapMethod, phase2); err != nil {
+			return err
+		}
+	} else if eapMethod != "" || phase2 != "" || wifiAnonIdentity != "" || wifiIdentity != "" {
+		return fmt.Errorf("EAP options require --encryption WPA2-EAP, got %s", encryption)
+	}
+	if wifiTransitionDisable && encryption != encoder.WPA && encryption != encoder.SAE {
+		return fmt.Errorf("--transition-disable requires WPA or SAE encryption, got %s", encryption)
+	}
+
 	wifi := &encoder.WiFi{
-		SSID:       wifiSSID,
-		Password:   wifiPassword,
-		Encryption: encryption,
-		Hidden:     wifiHidden,
+		SSID:              wifiSSID,
+		Password:          wifiPassword,
+		Encryption:        encryption,
+		Hidden:            wifiHidden,
+		TransitionDisable: wifiTransitionDisable,
+		EAPMethod:         eapMethod,
+		Phase2:            phase2,
+		AnonymousIdentity: wifiAnonIdentity,
+		Identity:          wifiIdentity,
 	}
 
 	content := wifi.Encode()
 
 	if !quiet {
-		fmt.Fprintf(cmd.ErrOrStderr(), "WiFi: %s (%s)\n", wifiSSID, encryption)
+		if eapMethod != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "WiFi: %s (%s, %s)\n", wifiSSID, encryption, eapMethod)
+		} else {
+			fmt.Fprintf(cmd.ErrOrStderr(), "WiFi: %s (%s)\n", wifiSSID, encryption)
+		}
+	}
+
+	return generateQR(content)
+}
+
+// loadWiFiConfig fills the network flags the user did not set from the
+// --from file
+func loadWiFiConfig(cmd *cobra.Command) error {
+	data, err := os.ReadFile(wifiFrom)
+	if err != nil {
+		return fmt.Errorf("failed to read network config: %w", err)
+	}
+	networks, err := encoder.ParseWiFiConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", wifiFrom, err)
+	}
+
+	var network *encoder.WiFi
+	ssids := make([]string, len(networks))
+	for i, n := range networks {
+		ssids[i] = fmt.Sprintf("%q", n.SSID)
+		if n.SSID == wifiSSID || (wifiSSID == "" && len(networks) == 1) {
+			network = n
+			break
+		}
+	}
+	if network == nil {
+		switch {
+		case len(networks) == 0:
+			return fmt.Errorf("no networks found in %s", wifiFrom)
+		case wifiSSID != "":
+			return fmt.Errorf("no network %q in %s", wifiSSID, wifiFrom)
+		default:
+			return fmt.Errorf("%s has %d networks, choose one with --ssid: %s", wifiFrom, len(networks), strings.Join(ssids, ", "))
+		}
+	}
+
+	flags := cmd.Flags()
+	wifiSSID = network.SSID
+	if !flags.Changed("encryption") {
+		wifiEncryption = string(network.Encryption)
+	}
+	if !secretGiven(cmd, "password") {
+		if network.Password == "" && network.Encryption != encoder.NoPass && network.Encryption != encoder.WPA2EAP {
+			return fmt.Errorf("%s does not store the password of %q (it may be kept in a keyring); pass it with --password", wifiFrom, network.SSID)
+		}
+		wifiPassword = network.Password
+	}
+	if !flags.Changed("hidden") {
+		wifiHidden = network.Hidden
+	}
+	for _, f := range []struct {
+		name  string
+		value *string
+		file  string
+	}{
+		{"eap-method", &wifiEAPMethod, network.EAPMethod},
+		{"phase2", &wifiPhase2, network.Phase2},
+		{"anonymous-identity", &wifiAnonIdentity, network.AnonymousIdentity},
+		{"identity", &wifiIdentity, network.Identity},
+	} {
+		if !flags.Changed(f.name) {
+			*f.value = f.file
+		}
+	}
+	return nil
+}
+
+func runDPP(cmd *cobra.Command) error {
+	for _, name := range []string{"from", "ssid", "password", "password-file", "password-env", "encryption",
+		"hidden", "transition-disable", "eap-method", "phase2", "anonymous-identity", "identity"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --dpp", name)
+		}
+	}
+
+	var publicKey string
+	switch {
+	case wifiDPPKey != "" && wifiDPPKeygen != "":
+		return fmt.Errorf("use either --dpp-key or --dpp-keygen, not both")
+	case wifiDPPKey != "":
+		data, err := os.ReadFile(wifiDPPKey)
+		if err != nil {
+			return fmt.Errorf("failed to read DPP key: %w", err)
+		}
+		publicKey, err = encoder.ParseDPPKey(data)
+		if err != nil {
+			return err
+		}
+	case wifiDPPKeygen != "":
+		// The key is generated and written below
+	default:
+		return fmt.Errorf("--dpp requires --dpp-key or --dpp-keygen")
+	}
+
+	dpp := &encoder.DPP{
+		Channels:  wifiChannels,
+		MAC:       wifiMAC,
+		Info:      wifiInfo,
+		Version:   wifiDPPVersion,
+		PublicKey: publicKey,
+	}
+
+	if wifiDPPKeygen != "" {
+		privatePEM, key, err := encoder.GenerateDPPKey()
+		if err != nil {
+			return err
+		}
+		bootstrapKey, err := encoder.DPPBootstrapKey(privatePEM)
+		if err != nil {
+			return err
+		}
+		dpp.PublicKey = key
+		// Validate before writing so a bad --mac does not leave an orphan key
+		if err := dpp.Validate(); err != nil {
+			return err
+		}
+		if err := writeNewFile(wifiDPPKeygen, privatePEM); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				return fmt.Errorf("%s already exists; use --dpp-key to reuse it", wifiDPPKeygen)
+			}
+			return fmt.Errorf("failed to write DPP key: %w", err)
+		}
+		if !quiet {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Private key saved to: %s\n", wifiDPPKeygen)
+			fmt.Fprintf(cmd.ErrOrStderr(), "hostapd/wpa_supplicant: dpp_bootstrap_gen type=qrcode key=%s\n", bootstrapKey)
+		}
+	} else if err := dpp.Validate(); err != nil {
+		return err
+	}
+
+	content := dpp.Encode()
+
+	if !quiet {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wi-Fi Easy Connect (DPP) bootstrapping URI\n")
 	}
 
 	return generateQR(content)
 }
+
+// writeNewFile writes data to a file only the owner can read, failing
+// with os.ErrExist rather than overwriting one, such as a key a device may
+// already be provisioned with
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -3,6 +3,7 @@ package encoder
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -82,3 +83,109 @@ func (g *Geo) Encode() string {
 	}
 	return fmt.Sprintf("geo:%f,%f", g.Latitude, g.Longitude)
 }
+
+// ParseEmail parses a mailto: URL back into an Email
+func ParseEmail(s string) (*Email, error) {
+	rest, ok := cutPrefixFold(strings.TrimSpace(s), "mailto:")
+	if !ok {
+		return nil, fmt.Errorf("not an email payload: missing mailto: prefix")
+	}
+
+	addr, rawQuery, _ := strings.Cut(rest, "?")
+	to, err := url.PathUnescape(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email address: %w", err)
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mailto parameters: %w", err)
+	}
+
+	e := &Email{To: to}
+	for key, values := range query {
+		switch strings.ToLower(key) {
+		case "to":
+			// Extra recipients may be given as a parameter too
+			e.To = strings.Trim(e.To+","+values[0], ",")
+		case "cc":
+			e.CC = values[0]
+		case "bcc":
+			e.BCC = values[0]
+		case "subject":
+			e.Subject = values[0]
+		case "body":
+			e.Body = values[0]
+		}
+	}
+	return e, nil
+}
+
+// ParseSMS parses an sms: URL, or the SMSTO:number:body form, back into
+// an SMS
+func ParseSMS(s string) (*SMS, error) {
+	s = strings.TrimSpace(s)
+
+	if rest, ok := cutPrefixFold(s, "smsto:"); ok {
+		number, body, _ := strings.Cut(rest, ":")
+		return &SMS{Number: number, Body: body}, nil
+	}
+
+	rest, ok := cutPrefixFold(s, "sms:")
+	if !ok {
+		return nil, fmt.Errorf("not an SMS payload: missing sms: prefix")
+	}
+	number, rawQuery, _ := strings.Cut(rest, "?")
+	// iOS writes sms:number;?body=...
+	number = strings.TrimSuffix(number, ";")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sms parameters: %w", err)
+	}
+	sms := &SMS{Number: number}
+	for key, values := range query {
+		if strings.EqualFold(key, "body") {
+			sms.Body = values[0]
+		}
+	}
+	return sms, nil
+}
+
+// ParseGeo parses a geo: URI back into a Geo. An altitude and URI
+// parameters such as ;u= are accepted and dropped.
+func ParseGeo(s string) (*Geo, error) {
+	rest, ok := cutPrefixFold(strings.TrimSpace(s), "geo:")
+	if !ok {
+		return nil, fmt.Errorf("not a geo payload: missing geo: prefix")
+	}
+
+	coords, rawQuery, _ := strings.Cut(rest, "?")
+	coords, _, _ = strings.Cut(coords, ";")
+	parts := strings.Split(coords, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid geo coordinates: %s", coords)
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %s", parts[0])
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %s", parts[1])
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid geo parameters: %w", err)
+	}
+	return &Geo{Latitude: lat, Longitude: lon, Query: query.Get("q")}, nil
+}
+
+// cutPrefixFold is strings.CutPrefix with a case-insensitive prefix
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -180,3 +180,112 @@ func TestGeoEncode(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEmail(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Email
+		hasError bool
+	}{
+		{"mailto:test@example.com", Email{To: "test@example.com"}, false},
+		{"MAILTO:a@example.com?subject=Hello+World&body=Line%201%0ALine%202&cc=b@example.com&bcc=c@example.com",
+			Email{To: "a@example.com", Subject: "Hello World", Body: "Line 1\nLine 2", CC: "b@example.com", BCC: "c@example.com"}, false},
+		{"mailto:?to=x@example.com&Subject=Hi", Email{To: "x@example.com", Subject: "Hi"}, false},
+		{"test@example.com", Email{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseEmail(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseEmail(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEmail(%q) unexpected error: %v", tt.input, err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseEmail(%q) = %+v, want %+v", tt.input, *result, tt.expected)
+			}
+		})
+	}
+
+	original := Email{To: "a@example.com", CC: "b@example.com", Subject: "Q&A = fun?", Body: "100% sure\n+1"}
+	if parsed, err := ParseEmail(original.Encode()); err != nil || *parsed != original {
+		t.Errorf("ParseEmail(Encode()) = %+v, %v; want %+v", parsed, err, original)
+	}
+}
+
+func TestParseSMS(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SMS
+		hasError bool
+	}{
+		{"sms:+1234567890", SMS{Number: "+1234567890"}, false},
+		{"sms:+1234567890?body=Hello+there%21", SMS{Number: "+1234567890", Body: "Hello there!"}, false},
+		{"sms:+1234567890;?body=iOS", SMS{Number: "+1234567890", Body: "iOS"}, false},
+		{"SMSTO:+1234567890:Hi: there", SMS{Number: "+1234567890", Body: "Hi: there"}, false},
+		{"tel:+1234567890", SMS{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSMS(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseSMS(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSMS(%q) unexpected error: %v", tt.input, err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseSMS(%q) = %+v, want %+v", tt.input, *result, tt.expected)
+			}
+		})
+	}
+
+	original := SMS{Number: "+1234567890", Body: "Call me & text back"}
+	if parsed, err := ParseSMS(original.Encode()); err != nil || *parsed != original {
+		t.Errorf("ParseSMS(Encode()) = %+v, %v; want %+v", parsed, err, original)
+	}
+}
+
+func TestParseGeo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Geo
+		hasError bool
+	}{
+		{"geo:37.774900,-122.419400", Geo{Latitude: 37.7749, Longitude: -122.4194}, false},
+		{"geo:48.8584,2.2945?q=Eiffel+Tower", Geo{Latitude: 48.8584, Longitude: 2.2945, Query: "Eiffel Tower"}, false},
+		{"GEO:1.5,2.5,100;u=35", Geo{Latitude: 1.5, Longitude: 2.5}, false},
+		{"geo:91,0", Geo{}, true},
+		{"geo:0,181", Geo{}, true},
+		{"geo:abc,0", Geo{}, true},
+		{"geo:1", Geo{}, true},
+		{"1.5,2.5", Geo{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseGeo(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseGeo(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGeo(%q) unexpected error: %v", tt.input, err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseGeo(%q) = %+v, want %+v", tt.input, *result, tt.expected)
+			}
+		})
+	}
+}
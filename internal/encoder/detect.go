@@ -1,12 +1,17 @@
 package encoder
 
 import (
+	"errors"
 	"regexp"
 	"strings"
 )
 
-// Detect analyzes input and returns its content type
-func Detect(input string) ContentType {
+// Detect analyzes input and returns its content type. Proxy share links
+// are parsed as well: the error reports every problem that would make a
+// client reject the link, such as undecodable vmess JSON, a malformed
+// server, port or credentials, or an unknown cipher or transport. It is
+// nil for valid links and all other types.
+func Detect(input string) (ContentType, error) {
 	input = strings.TrimSpace(input)
 
 	// Check for specific protocols/prefixes
@@ -15,84 +20,92 @@ func Detect(input string) ContentType {
 	// Proxy protocols
 	proxyPrefixes := []string{
 		"vmess://", "vless://", "ss://", "ssr://",
-		"trojan://", "hysteria://", "hysteria2://",
+		"trojan://", "hysteria://", "hysteria2://", "hy2://",
 		"tuic://", "naive+https://", "nekoray://",
 	}
 	for _, prefix := range proxyPrefixes {
 		if strings.HasPrefix(lowerInput, prefix) {
-			return TypeProxy
+			_, problems := parseProxy(input)
+			return TypeProxy, errors.Join(problems...)
 		}
 	}
 
 	// URL
 	if strings.HasPrefix(lowerInput, "http://") || strings.HasPrefix(lowerInput, "https://") {
-		return TypeURL
+		return TypeURL, nil
 	}
 
 	// WiFi
 	if strings.HasPrefix(strings.ToUpper(input), "WIFI:") {
-		return TypeWiFi
+		return TypeWiFi, nil
+	}
+
+	// Wi-Fi Easy Connect
+	if strings.HasPrefix(strings.ToUpper(input), "DPP:") {
+		return TypeDPP, nil
 	}
 
 	// OTP
 	if strings.HasPrefix(lowerInput, "otpauth://") {
-		return TypeOTP
+		return TypeOTP, nil
 	}
 
 	// Email (mailto:)
 	if strings.HasPrefix(lowerInput, "mailto:") {
-		return TypeEmail
+		return TypeEmail, nil
 	}
 
 	// Phone (tel:)
 	if strings.HasPrefix(lowerInput, "tel:") {
-		return TypePhone
+		return TypePhone, nil
 	}
 
 	// SMS
 	if strings.HasPrefix(lowerInput, "sms:") || strings.HasPrefix(lowerInput, "smsto:") {
-		return TypeSMS
+		return TypeSMS, nil
 	}
 
 	// Geo
 	if strings.HasPrefix(lowerInput, "geo:") {
-		return TypeGeo
+		return TypeGeo, nil
 	}
 
 	// vCard
 	if strings.HasPrefix(strings.ToUpper(input), "BEGIN:VCARD") {
-		return TypeVCard
+		return TypeVCard, nil
 	}
 
 	// Calendar event
 	if strings.HasPrefix(strings.ToUpper(input), "BEGIN:VEVENT") {
-		return TypeEvent
+		return TypeEvent, nil
 	}
 
 	// Check if it looks like a URL without protocol
 	urlPattern := regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z]{2,})+(/.*)?$`)
 	if urlPattern.MatchString(input) {
-		return TypeURL
+		return TypeURL, nil
 	}
 
 	// Check if it looks like an email address
 	emailPattern := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if emailPattern.MatchString(input) {
-		return TypeEmail
+		return TypeEmail, nil
 	}
 
 	// Default to plain text
-	return TypeText
+	return TypeText, nil
 }
 
-// DetectAndDescribe returns the detected type and a human-readable description
-func DetectAndDescribe(input string) (ContentType, string) {
-	contentType := Detect(input)
+// DetectAndDescribe returns the detected type and a human-readable
+// description, with the problems Detect found in a proxy share link
+func DetectAndDescribe(input string) (ContentType, string, error) {
+	contentType, err := Detect(input)
 
 	descriptions := map[ContentType]string{
 		TypeText:    "Plain text",
 		TypeURL:     "URL",
 		TypeWiFi:    "WiFi network",
+		TypeDPP:     "Wi-Fi Easy Connect (DPP)",
 		TypeVCard:   "Contact card (vCard)",
 		TypeEmail:   "Email",
 		TypePhone:   "Phone number",
@@ -104,5 +117,5 @@ func DetectAndDescribe(input string) (ContentType, string) {
 		TypeUnknown: "Unknown",
 	}
 
-	return contentType, descriptions[contentType]
+	return contentType, descriptions[contentType], err
 }
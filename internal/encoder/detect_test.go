@@ -1,6 +1,9 @@
 package encoder
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestDetect(t *testing.T) {
 	tests := []struct {
@@ -22,6 +25,7 @@ func TestDetect(t *testing.T) {
 		{"trojan://password@host:port", TypeProxy},
 		{"hysteria://host:port", TypeProxy},
 		{"hysteria2://host:port", TypeProxy},
+		{"hy2://host:port", TypeProxy},
 
 		// WiFi
 		{"WIFI:T:WPA;S:MyNetwork;P:password;;", TypeWiFi},
@@ -50,6 +54,9 @@ func TestDetect(t *testing.T) {
 		{"BEGIN:VCARD\nVERSION:3.0\nFN:John Doe\nEND:VCARD", TypeVCard},
 		{"begin:vcard\nversion:3.0", TypeVCard},
 
+		// Wi-Fi Easy Connect
+		{"DPP:V:2;K:MDkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDIgADURzxmttZoIRIPWGoQMV00XHWCAQIhXruVWOz0NjlkIA=;;", TypeDPP},
+
 		// Calendar event
 		{"BEGIN:VEVENT\nSUMMARY:Meeting\nEND:VEVENT", TypeEvent},
 
@@ -61,7 +68,7 @@ func TestDetect(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input[:min(len(tt.input), 30)], func(t *testing.T) {
-			result := Detect(tt.input)
+			result, _ := Detect(tt.input)
 			if result != tt.expected {
 				t.Errorf("Detect(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
@@ -69,6 +76,49 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestDetectProxyLinks(t *testing.T) {
+	vmess := (&VMess{Address: "example.com", Port: 443, ID: testUUID}).Encode()
+
+	tests := []struct {
+		name    string
+		input   string
+		problem string // Empty for a valid link
+	}{
+		{"vmess", vmess, ""},
+		{"vmess truncated", vmess[:len(vmess)-20], "vmess link"},
+		{"vmess placeholder", "vmess://base64content", "vmess link"},
+		{"ss truncated", "ss://YWVzLTEyOC1nY206dGVzdA@192.168.100.1", "missing port"},
+		{"vless without server", "vless://" + testUUID, "missing port"},
+		{"trojan", "trojan://secret@example.com:443", ""},
+		{"hy2", "hy2://letmein@example.com:443/?insecure=1", ""},
+		{"hy2 bad range", "hy2://letmein@example.com:443,6000-5000/", "invalid port range"},
+		{"empty", "vless://", "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, err := Detect(tt.input)
+			if contentType != TypeProxy {
+				t.Errorf("Detect(%q) = %q, want %q", tt.input, contentType, TypeProxy)
+			}
+			if tt.problem == "" {
+				if err != nil {
+					t.Errorf("Detect(%q) unexpected error: %v", tt.input, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("Detect(%q) error = %v, want it to mention %q", tt.input, err, tt.problem)
+			}
+		})
+	}
+
+	// Only proxy links are parsed
+	if _, err := Detect("https://example.com"); err != nil {
+		t.Errorf("Detect() of a URL unexpected error: %v", err)
+	}
+}
+
 func TestDetectAndDescribe(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -82,7 +132,7 @@ func TestDetectAndDescribe(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			contentType, description := DetectAndDescribe(tt.input)
+			contentType, description, _ := DetectAndDescribe(tt.input)
 			if contentType != tt.expectedType {
 				t.Errorf("DetectAndDescribe(%q) type = %q, want %q", tt.input, contentType, tt.expectedType)
 			}
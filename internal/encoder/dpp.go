@@ -0,0 +1,264 @@
+package encoder
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DPP encodes a Wi-Fi Easy Connect (Device Provisioning Protocol)
+// bootstrapping URI, which a configurator scans to onboard a device
+type DPP struct {
+	Channels  []string // Global operating class/channel pairs, e.g. 81/1
+	MAC       string
+	Info      string
+	Version   int    // DPP protocol version, 0 to omit
+	PublicKey string // Base64 DER SubjectPublicKeyInfo of the bootstrapping key
+}
+
+// Encode returns the DPP URI
+// Format: DPP:C:<class/channel,...>;M:<mac>;I:<info>;V:<version>;K:<key>;;
+func (d *DPP) Encode() string {
+	var b strings.Builder
+	b.WriteString("DPP:")
+	if len(d.Channels) > 0 {
+		b.WriteString("C:" + strings.Join(d.Channels, ",") + ";")
+	}
+	if d.MAC != "" {
+		b.WriteString("M:" + normalizeMAC(d.MAC) + ";")
+	}
+	if d.Info != "" {
+		b.WriteString("I:" + d.Info + ";")
+	}
+	if d.Version > 0 {
+		b.WriteString("V:" + strconv.Itoa(d.Version) + ";")
+	}
+	b.WriteString("K:" + d.PublicKey + ";;")
+	return b.String()
+}
+
+var (
+	dppChannelPattern = regexp.MustCompile(`^[0-9]{1,3}/[0-9]{1,3}$`)
+	macPattern        = regexp.MustCompile(`^[0-9a-f]{12}$`)
+)
+
+// Validate checks the fields against the URI grammar of the Wi-Fi Easy
+// Connect specification
+func (d *DPP) Validate() error {
+	for _, c := range d.Channels {
+		if !dppChannelPattern.MatchString(c) {
+			return fmt.Errorf("invalid DPP channel %q (use operating class/channel, e.g. 81/1)", c)
+		}
+	}
+	if d.MAC != "" && !macPattern.MatchString(normalizeMAC(d.MAC)) {
+		return fmt.Errorf("invalid MAC address: %s", d.MAC)
+	}
+	for _, r := range d.Info {
+		// info = *(%x20-3A / %x3C-7E), printable ASCII without ;
+		if r < 0x20 || r > 0x7e || r == ';' {
+			return fmt.Errorf("DPP information may only contain printable ASCII without ';'")
+		}
+	}
+	if d.Version < 0 {
+		return fmt.Errorf("invalid DPP version: %d", d.Version)
+	}
+	if d.PublicKey == "" {
+		return fmt.Errorf("DPP URI requires a public key")
+	}
+	if _, err := parseDPPPublicKey(d.PublicKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeMAC lowercases a MAC address and drops its separators, the
+// form DPP URIs use
+func normalizeMAC(mac string) string {
+	return strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.ToLower(mac))
+}
+
+// p256SPKIPrefix is the DER SubjectPublicKeyInfo header for a compressed
+// P-256 point: the id-ecPublicKey and prime256v1 OIDs and a 34 byte bit
+// string. x509.MarshalPKIXPublicKey only writes uncompressed points, but
+// DPP requires the compressed form.
+var p256SPKIPrefix = []byte{
+	0x30, 0x39, 0x30, 0x13,
+	0x06, 0x07, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x02, 0x01,
+	0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07,
+	0x03, 0x22, 0x00,
+}
+
+// DPPPublicKey returns the base64 SubjectPublicKeyInfo of a P-256 public
+// key as it appears in the K: field
+func DPPPublicKey(pub *ecdsa.PublicKey) (string, error) {
+	if pub.Curve != elliptic.P256() {
+		return "", fmt.Errorf("DPP bootstrapping keys must be P-256, got %s", pub.Curve.Params().Name)
+	}
+	key, err := pub.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	// Uncompressed point: 0x04 || X || Y
+	point := key.Bytes()
+	x, y := point[1:33], point[33:]
+	compressed := append([]byte{0x02 | y[31]&1}, x...)
+
+	return base64.StdEncoding.EncodeToString(append(append([]byte{}, p256SPKIPrefix...), compressed...)), nil
+}
+
+// GenerateDPPKey creates a new P-256 bootstrapping key pair. It returns the
+// private key as a PEM encoded SEC 1 ECPrivateKey and the public key for
+// the K: field. DPPBootstrapKey converts the private key to the form
+// hostapd and wpa_supplicant take.
+func GenerateDPPKey() (privatePEM []byte, publicKey string, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicKey, err = DPPPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), publicKey, nil
+}
+
+// DPPBootstrapKey returns a PEM encoded EC private key as hex DER, the
+// form hostapd and wpa_supplicant take in dpp_bootstrap_gen key=
+func DPPBootstrapKey(privatePEM []byte) (string, error) {
+	block, _ := pem.Decode(privatePEM)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return "", fmt.Errorf("not a PEM encoded EC private key")
+	}
+	if _, err := x509.ParseECPrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return hex.EncodeToString(block.Bytes), nil
+}
+
+// ParseDPPKey reads a bootstrapping key from a PEM private or public key,
+// or from the base64 SubjectPublicKeyInfo itself, and returns the value
+// for the K: field
+func ParseDPPKey(data []byte) (string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		key := strings.Join(strings.Fields(string(data)), "")
+		if _, err := parseDPPPublicKey(key); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+
+	var pub any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		priv, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse private key: %w", err)
+		}
+		pub = &priv.PublicKey
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse private key: %w", err)
+		}
+		if signer, ok := priv.(*ecdsa.PrivateKey); ok {
+			pub = &signer.PublicKey
+		} else {
+			pub = priv
+		}
+	case "PUBLIC KEY":
+		var err error
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse public key: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("unsupported PEM block %q (use an EC private or public key)", block.Type)
+	}
+
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("DPP bootstrapping keys must be elliptic curve keys, got %T", pub)
+	}
+	return DPPPublicKey(ecPub)
+}
+
+// parseDPPPublicKey decodes a K: field value
+func parseDPPPublicKey(key string) (*ecdh.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("DPP public key is not valid base64: %w", err)
+	}
+	if len(der) != len(p256SPKIPrefix)+33 || string(der[:len(p256SPKIPrefix)]) != string(p256SPKIPrefix) {
+		// Fall back to the generic parser for uncompressed or other curves
+		pub, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("DPP public key is not a SubjectPublicKeyInfo: %w", err)
+		}
+		ecPub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("DPP public key is not an elliptic curve key")
+		}
+		return ecPub.ECDH()
+	}
+
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), der[len(p256SPKIPrefix):])
+	if x == nil {
+		return nil, fmt.Errorf("DPP public key is not a point on P-256")
+	}
+	return (&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}).ECDH()
+}
+
+// ParseDPP parses a DPP: bootstrapping URI. Fields may appear in any
+// order and unknown fields are ignored.
+func ParseDPP(s string) (*DPP, error) {
+	if !strings.HasPrefix(strings.ToUpper(s), "DPP:") {
+		return nil, fmt.Errorf("not a DPP URI: missing DPP: prefix")
+	}
+
+	d := &DPP{}
+	for _, field := range strings.Split(s[len("DPP:"):], ";") {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid DPP field: %s", field)
+		}
+		switch key {
+		case "C":
+			d.Channels = strings.Split(value, ",")
+		case "M":
+			d.MAC = value
+		case "I":
+			d.Info = value
+		case "V":
+			version, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DPP version: %s", value)
+			}
+			d.Version = version
+		case "K":
+			d.PublicKey = value
+		}
+	}
+
+	if d.PublicKey == "" {
+		return nil, fmt.Errorf("DPP URI has no public key")
+	}
+	return d, nil
+}
@@ -0,0 +1,179 @@
+package encoder
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+// Bootstrapping key from the example URI in the Wi-Fi Easy Connect specification
+const testDPPKey = "MDkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDIgADURzxmttZoIRIPWGoQMV00XHWCAQIhXruVWOz0NjlkIA="
+
+func TestDPPEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		dpp      DPP
+		expected string
+	}{
+		{
+			name:     "key only",
+			dpp:      DPP{PublicKey: testDPPKey},
+			expected: "DPP:K:" + testDPPKey + ";;",
+		},
+		{
+			name: "all fields",
+			dpp: DPP{
+				Channels:  []string{"81/1", "115/36"},
+				MAC:       "52:54:00:58:28:E5",
+				Info:      "SN=4774LH2b4044",
+				Version:   2,
+				PublicKey: testDPPKey,
+			},
+			expected: "DPP:C:81/1,115/36;M:5254005828e5;I:SN=4774LH2b4044;V:2;K:" + testDPPKey + ";;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.dpp.Encode()
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+			if err := tt.dpp.Validate(); err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDPPValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		dpp  DPP
+	}{
+		{"bad channel", DPP{Channels: []string{"81-1"}, PublicKey: testDPPKey}},
+		{"bad MAC", DPP{MAC: "52:54:00:58:28", PublicKey: testDPPKey}},
+		{"semicolon in info", DPP{Info: "a;b", PublicKey: testDPPKey}},
+		{"non-ASCII info", DPP{Info: "café", PublicKey: testDPPKey}},
+		{"missing key", DPP{}},
+		{"bad base64", DPP{PublicKey: "not base64!"}},
+		{"not a point", DPP{PublicKey: base64.StdEncoding.EncodeToString(append(append([]byte{}, p256SPKIPrefix...), make([]byte, 33)...))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.dpp.Validate(); err == nil {
+				t.Errorf("Validate() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGenerateDPPKey(t *testing.T) {
+	privatePEM, publicKey, err := GenerateDPPKey()
+	if err != nil {
+		t.Fatalf("GenerateDPPKey() unexpected error: %v", err)
+	}
+
+	der, _ := base64.StdEncoding.DecodeString(publicKey)
+	if len(der) != 59 {
+		t.Errorf("public key is %d bytes, want 59 (compressed P-256)", len(der))
+	}
+	if _, err := parseDPPPublicKey(publicKey); err != nil {
+		t.Errorf("parseDPPPublicKey() unexpected error: %v", err)
+	}
+
+	// The private key file must yield the same public key
+	fromPrivate, err := ParseDPPKey(privatePEM)
+	if err != nil {
+		t.Fatalf("ParseDPPKey() unexpected error: %v", err)
+	}
+	if fromPrivate != publicKey {
+		t.Errorf("ParseDPPKey(private) = %q, want %q", fromPrivate, publicKey)
+	}
+
+	// hostapd takes the DER ECPrivateKey as hex, not the PEM file
+	bootstrap, err := DPPBootstrapKey(privatePEM)
+	if err != nil {
+		t.Fatalf("DPPBootstrapKey() unexpected error: %v", err)
+	}
+	block, _ := pem.Decode(privatePEM)
+	if bootstrap != hex.EncodeToString(block.Bytes) {
+		t.Errorf("DPPBootstrapKey() = %q, want the hex of the PEM body", bootstrap)
+	}
+	if _, err := DPPBootstrapKey([]byte(testDPPKey)); err == nil {
+		t.Error("DPPBootstrapKey() of a public key expected error, got nil")
+	}
+}
+
+func TestParseDPPKey(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := DPPPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pkix, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p384DER, _ := x509.MarshalECPrivateKey(p384)
+
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+		hasError bool
+	}{
+		{"PKCS8 private key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), want, false},
+		{"PKIX public key", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}), want, false},
+		{"base64 key", []byte(testDPPKey + "\n"), testDPPKey, false},
+		{"P-384 key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: p384DER}), "", true},
+		{"certificate", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0}}), "", true},
+		{"garbage", []byte("hello"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDPPKey(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseDPPKey() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDPPKey() unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseDPPKey() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDPP(t *testing.T) {
+	original := DPP{Channels: []string{"81/1", "81/6"}, MAC: "5254005828e5", Info: "kitchen sensor", Version: 3, PublicKey: testDPPKey}
+	parsed, err := ParseDPP(original.Encode())
+	if err != nil {
+		t.Fatalf("ParseDPP() unexpected error: %v", err)
+	}
+	if strings.Join(parsed.Channels, ",") != "81/1,81/6" || parsed.MAC != original.MAC ||
+		parsed.Info != original.Info || parsed.Version != 3 || parsed.PublicKey != testDPPKey {
+		t.Errorf("ParseDPP(Encode()) = %+v, want %+v", *parsed, original)
+	}
+
+	for _, input := range []string{"DPP:C:81/1;;", "DPP:V:x;K:" + testDPPKey + ";;", "WIFI:S:x;;"} {
+		if _, err := ParseDPP(input); err == nil {
+			t.Errorf("ParseDPP(%q) expected error, got nil", input)
+		}
+	}
+}
@@ -12,6 +12,7 @@ const (
 	TypeText    ContentType = "text"
 	TypeURL     ContentType = "url"
 	TypeWiFi    ContentType = "wifi"
+	TypeDPP     ContentType = "dpp"
 	TypeVCard   ContentType = "vcard"
 	TypeEmail   ContentType = "email"
 	TypePhone   ContentType = "phone"
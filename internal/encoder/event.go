@@ -0,0 +1,189 @@
+package encoder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event encodes a calendar event as an iCalendar VEVENT (RFC 5545)
+type Event struct {
+	Summary     string
+	Start       time.Time
+	End         time.Time // Optional; for all-day events the last day of the event
+	AllDay      bool
+	Location    string
+	Description string
+	URL         string
+	Organizer   string // Email address
+	// Display name of the organizer, only used with Organizer
+	OrganizerName string
+	// Reminders, each the time before Start at which to alert
+	Alarms []time.Duration
+	// Unique identifier; by default derived from the summary and start, so
+	// a regenerated code updates the same calendar entry
+	UID string
+	// Creation time written as DTSTAMP, by default the time of encoding
+	Stamp time.Time
+}
+
+// Encode returns the VEVENT block. Times are converted to UTC, since a
+// TZID would need a VTIMEZONE definition that scanners could resolve.
+// Lines end in CRLF and are folded at 75 octets.
+func (e *Event) Encode() string {
+	var lines []string
+
+	uid := e.UID
+	if uid == "" {
+		uid = e.defaultUID()
+	}
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	lines = append(lines, "BEGIN:VEVENT")
+	lines = append(lines, "UID:"+escapeText(uid))
+	lines = append(lines, formatDateTime("DTSTAMP", stamp))
+	lines = append(lines, "SUMMARY:"+escapeText(e.Summary))
+
+	if e.AllDay {
+		lines = append(lines, "DTSTART;VALUE=DATE:"+e.Start.Format("20060102"))
+		// DTEND is exclusive: the day after the last day
+		last := e.Start
+		if !e.End.IsZero() {
+			last = e.End
+		}
+		lines = append(lines, "DTEND;VALUE=DATE:"+last.AddDate(0, 0, 1).Format("20060102"))
+	} else {
+		lines = append(lines, formatDateTime("DTSTART", e.Start))
+		if !e.End.IsZero() {
+			lines = append(lines, formatDateTime("DTEND", e.End))
+		}
+	}
+
+	if e.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	if e.URL != "" {
+		lines = append(lines, "URL:"+e.URL)
+	}
+	if e.Organizer != "" {
+		organizer := "ORGANIZER"
+		if e.OrganizerName != "" {
+			organizer += ";CN=" + quoteParam(e.OrganizerName)
+		}
+		lines = append(lines, organizer+":mailto:"+e.Organizer)
+	}
+
+	for _, before := range e.Alarms {
+		description := e.Summary
+		if description == "" {
+			description = "Reminder"
+		}
+		lines = append(lines,
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"DESCRIPTION:"+escapeText(description),
+			"TRIGGER:-"+formatDuration(before),
+			"END:VALARM",
+		)
+	}
+
+	lines = append(lines, "END:VEVENT")
+
+	for i, line := range lines {
+		lines[i] = foldLine(line)
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// defaultUID derives a UID from the summary and start of the event
+func (e *Event) defaultUID() string {
+	sum := sha256.Sum256([]byte(e.Summary + "\x00" + e.Start.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:16]) + "@mkqr"
+}
+
+// formatDateTime formats a DATE-TIME property in UTC
+func formatDateTime(name string, t time.Time) string {
+	return name + ":" + t.UTC().Format("20060102T150405Z")
+}
+
+// formatDuration formats d as an RFC 5545 duration such as PT15M or P1DT2H
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds > 0 || (hours == 0 && minutes == 0) {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+	}
+	return b.String()
+}
+
+// escapeText escapes an RFC 5545 TEXT value
+func escapeText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// quoteParam quotes a parameter value containing characters that are not
+// allowed bare. Double quotes cannot be escaped and are dropped.
+func quoteParam(s string) string {
+	s = strings.ReplaceAll(s, `"`, "")
+	if strings.ContainsAny(s, ";:,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// foldLine splits lines longer than 75 octets, continuing each with a
+// space, without breaking a UTF-8 sequence
+func foldLine(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		n := utf8.RuneLen(r)
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventEncode(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	start := time.Date(2026, 10, 17, 18, 30, 0, 0, berlin)
+	stamp := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	const header = "BEGIN:VEVENT\r\nUID:event@example.com\r\nDTSTAMP:20261001T120000Z\r\n"
+
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name: "timed event in a named zone converted to UTC",
+			event: Event{
+				Summary:  "Go Meetup",
+				Start:    start,
+				End:      start.Add(2 * time.Hour),
+				Location: "Room 1, Main St. 5",
+			},
+			expected: header +
+				"SUMMARY:Go Meetup\r\n" +
+				"DTSTART:20261017T163000Z\r\n" +
+				"DTEND:20261017T183000Z\r\n" +
+				`LOCATION:Room 1\, Main St. 5` + "\r\n" +
+				"END:VEVENT",
+		},
+		{
+			name: "fixed offset converted to UTC",
+			event: Event{
+				Summary: "Call",
+				Start:   time.Date(2026, 10, 17, 9, 0, 0, 0, time.FixedZone("+0200", 2*60*60)),
+			},
+			expected: header + "SUMMARY:Call\r\nDTSTART:20261017T070000Z\r\nEND:VEVENT",
+		},
+		{
+			name: "all-day event",
+			event: Event{
+				Summary: "Conference",
+				Start:   time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2026, 11, 4, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+			expected: header + "SUMMARY:Conference\r\n" +
+				"DTSTART;VALUE=DATE:20261102\r\nDTEND;VALUE=DATE:20261105\r\nEND:VEVENT",
+		},
+		{
+			name: "single all-day event",
+			event: Event{
+				Summary: "Holiday",
+				Start:   time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+			expected: header + "SUMMARY:Holiday\r\n" +
+				"DTSTART;VALUE=DATE:20261231\r\nDTEND;VALUE=DATE:20270101\r\nEND:VEVENT",
+		},
+		{
+			name: "organizer, URL and alarms",
+			event: Event{
+				Summary:       "Launch",
+				Start:         time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC),
+				Description:   "Bring laptops;\nslides are online",
+				URL:           "https://example.com/launch",
+				Organizer:     "events@example.com",
+				OrganizerName: "Doe, Jane",
+				Alarms:        []time.Duration{15 * time.Minute, 26 * time.Hour},
+			},
+			expected: header +
+				"SUMMARY:Launch\r\n" +
+				"DTSTART:20261017T090000Z\r\n" +
+				`DESCRIPTION:Bring laptops\;\nslides are online` + "\r\n" +
+				"URL:https://example.com/launch\r\n" +
+				`ORGANIZER;CN="Doe, Jane":mailto:events@example.com` + "\r\n" +
+				"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Launch\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\n" +
+				"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Launch\r\nTRIGGER:-P1DT2H\r\nEND:VALARM\r\n" +
+				"END:VEVENT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.UID, tt.event.Stamp = "event@example.com", stamp
+			result := tt.event.Encode()
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEventEncodeFolding(t *testing.T) {
+	e := Event{
+		Summary: strings.Repeat("Grüße ", 30),
+		Start:   time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC),
+	}
+	result := e.Encode()
+
+	var unfolded []string
+	for _, line := range strings.Split(result, "\r\n") {
+		if n := len(line); n > 75 {
+			t.Errorf("line is %d octets, want at most 75: %q", n, line)
+		}
+		if !strings.HasPrefix(line, " ") {
+			unfolded = append(unfolded, line)
+			continue
+		}
+		unfolded[len(unfolded)-1] += line[1:]
+	}
+	if want := "SUMMARY:" + e.Summary; unfolded[3] != want {
+		t.Errorf("unfolded SUMMARY = %q, want %q", unfolded[3], want)
+	}
+	if contentType, _ := Detect(result); contentType != TypeEvent {
+		t.Errorf("Detect(Encode()) = %q, want %q", contentType, TypeEvent)
+	}
+}
+
+func TestEventEncodeRequiredProperties(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	e := Event{Summary: "Dinner", Start: time.Date(2026, 10, 17, 19, 0, 0, 0, tokyo)}
+	result := e.Encode()
+
+	// Scanners need no VTIMEZONE to place a UTC time correctly
+	if !strings.Contains(result, "\r\nDTSTART:20261017T100000Z\r\n") || strings.Contains(result, "TZID") {
+		t.Errorf("Encode() = %q, want DTSTART in UTC without TZID", result)
+	}
+	if !strings.Contains(result, "\r\nDTSTAMP:") {
+		t.Errorf("Encode() = %q, want a DTSTAMP", result)
+	}
+	uid := ""
+	for _, line := range strings.Split(result, "\r\n") {
+		if strings.HasPrefix(line, "UID:") {
+			uid = line
+		}
+	}
+	if uid == "" || uid == "UID:" {
+		t.Fatalf("Encode() = %q, want a UID", result)
+	}
+
+	// The same event keeps its UID, another one gets a new one
+	if again := e.Encode(); !strings.Contains(again, uid+"\r\n") {
+		t.Errorf("Encode() UID changed between calls: %q, want %q", again, uid)
+	}
+	e.Start = e.Start.Add(time.Hour)
+	if other := e.Encode(); strings.Contains(other, uid+"\r\n") {
+		t.Errorf("Encode() of a later event reuses %s", uid)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{0, "PT0S"},
+		{30 * time.Second, "PT30S"},
+		{15 * time.Minute, "PT15M"},
+		{90 * time.Minute, "PT1H30M"},
+		{24 * time.Hour, "P1D"},
+		{50*time.Hour + 5*time.Minute, "P2DT2H5M"},
+		{-time.Hour, "PT1H"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := formatDuration(tt.input); result != tt.expected {
+				t.Errorf("formatDuration(%v) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package encoder
+
+import (
+	"encoding/base32"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Field is one named value of a parsed payload
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Inspection explains a payload: its type, the fields it carries and any
+// departures from the format's specification
+type Inspection struct {
+	Type        ContentType
+	Description string
+	Fields      []Field
+	Warnings    []string
+}
+
+func (in *Inspection) add(name, value string) {
+	in.Fields = append(in.Fields, Field{Name: name, Value: value})
+}
+
+func (in *Inspection) warn(format string, args ...any) {
+	in.Warnings = append(in.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Inspect detects the type of payload and breaks it down field by field.
+// An error is returned when the payload claims a type (by its prefix) but
+// cannot be parsed as one.
+func Inspect(payload string) (*Inspection, error) {
+	in := &Inspection{}
+	// Problems in proxy links are reported as warnings by inspectProxy
+	in.Type, in.Description, _ = DetectAndDescribe(payload)
+	payload = strings.TrimSpace(payload)
+
+	var err error
+	switch in.Type {
+	case TypeWiFi:
+		err = inspectWiFi(in, payload)
+	case TypeDPP:
+		err = inspectDPP(in, payload)
+	case TypeOTP:
+		err = inspectOTP(in, payload)
+	case TypeVCard:
+		err = inspectVCard(in, payload)
+	case TypeEmail:
+		err = inspectEmail(in, payload)
+	case TypeSMS:
+		err = inspectSMS(in, payload)
+	case TypeGeo:
+		err = inspectGeo(in, payload)
+	case TypeEvent:
+		inspectEvent(in, payload)
+	case TypeProxy:
+		inspectProxy(in, payload)
+	case TypePhone:
+		inspectPhone(in, payload)
+	case TypeURL:
+		inspectURL(in, payload)
+	default:
+		in.add("Length", fmt.Sprintf("%d characters", len([]rune(payload))))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func inspectWiFi(in *Inspection, payload string) error {
+	w, err := ParseWiFi(payload)
+	if err != nil {
+		return err
+	}
+
+	in.add("SSID", w.SSID)
+	in.add("Encryption", string(w.Encryption))
+	if w.Password != "" {
+		in.add("Password", w.Password)
+	}
+	for _, f := range []Field{
+		{"EAP method", w.EAPMethod},
+		{"Phase 2", w.Phase2},
+		{"Anonymous identity", w.AnonymousIdentity},
+		{"Identity", w.Identity},
+	} {
+		if f.Value != "" {
+			in.add(f.Name, f.Value)
+		}
+	}
+	in.add("Hidden", strconv.FormatBool(w.Hidden))
+	if w.TransitionDisable {
+		in.add("Transition disable", "WPA3 only once joined")
+	}
+
+	if n := len(w.SSID); n > 32 {
+		in.warn("SSID is %d bytes, the 802.11 limit is 32", n)
+	}
+	switch w.Encryption {
+	case NoPass:
+		if w.Password != "" {
+			in.warn("password is set on an open (nopass) network and will be ignored")
+		}
+	case WPA:
+		if !validWPAPassphrase(w.Password) {
+			in.warn("WPA passphrase must be 8-63 characters or 64 hex digits, got %d characters", len(w.Password))
+		}
+	case SAE:
+		if len(w.Password) < 8 {
+			in.warn("WPA3-SAE password should be at least 8 characters, got %d", len(w.Password))
+		}
+	case WPA2EAP:
+		if w.EAPMethod == "" {
+			in.warn("WPA2-EAP network has no EAP method (E:)")
+		} else if err := ValidateEAP(w.EAPMethod, w.Phase2); err != nil {
+			in.warn("%v", err)
+		}
+		if w.Identity == "" {
+			in.warn("WPA2-EAP network has no identity (I:)")
+		}
+	case WEP:
+		if !validWEPKey(w.Password) {
+			in.warn("WEP key must be 5 or 13 characters, or 10 or 26 hex digits")
+		}
+	}
+	if w.Encryption != WPA2EAP && (w.EAPMethod != "" || w.Phase2 != "" || w.AnonymousIdentity != "" || w.Identity != "") {
+		in.warn("EAP fields are set on a %s network and will be ignored", w.Encryption)
+	}
+	if w.TransitionDisable && w.Encryption != WPA && w.Encryption != SAE {
+		in.warn("transition disable flag only applies to WPA3 Personal networks")
+	}
+
+	fields := splitEscaped(payload[len("WIFI:"):], ';')
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, ":")
+		switch strings.ToUpper(key) {
+		case "", "T", "S", "P", "E", "PH2", "A", "I":
+		case "R":
+			if _, err := strconv.ParseUint(value, 16, 8); err != nil {
+				in.warn("transition disable flag should be a hex bitmap such as 1, got %q", value)
+			}
+		case "H":
+			if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+				in.warn("hidden flag should be true or false, got %q", value)
+			}
+		default:
+			in.warn("unknown field %q", key)
+		}
+	}
+	// A terminated payload splits into two empty trailing fields
+	if n := len(fields); n < 2 || fields[n-1] != "" || fields[n-2] != "" {
+		in.warn("payload should end with ;;")
+	}
+	return nil
+}
+
+func inspectDPP(in *Inspection, payload string) error {
+	d, err := ParseDPP(payload)
+	if err != nil {
+		return err
+	}
+
+	if len(d.Channels) > 0 {
+		in.add("Channels", strings.Join(d.Channels, ", "))
+	}
+	if d.MAC != "" {
+		in.add("MAC", d.MAC)
+	}
+	if d.Info != "" {
+		in.add("Info", d.Info)
+	}
+	if d.Version > 0 {
+		in.add("Version", strconv.Itoa(d.Version))
+	}
+	in.add("Public key", d.PublicKey)
+
+	if err := d.Validate(); err != nil {
+		in.warn("%v", err)
+	}
+	if !strings.HasSuffix(payload, ";;") {
+		in.warn("payload should end with ;;")
+	}
+	return nil
+}
+
+var hexPattern = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+func validWPAPassphrase(p string) bool {
+	if len(p) == 64 {
+		return hexPattern.MatchString(p)
+	}
+	return len(p) >= 8 && len(p) <= 63
+}
+
+func validWEPKey(k string) bool {
+	switch len(k) {
+	case 5, 13:
+		return true
+	case 10, 26:
+		return hexPattern.MatchString(k)
+	}
+	return false
+}
+
+func inspectOTP(in *Inspection, payload string) error {
+	o, err := ParseOTP(payload)
+	if err != nil {
+		return err
+	}
+
+	in.add("Type", string(o.Type))
+	if o.Issuer != "" {
+		in.add("Issuer", o.Issuer)
+	}
+	in.add("Account", o.Account)
+	in.add("Secret", o.Secret)
+	in.add("Algorithm", o.Algorithm)
+	in.add("Digits", strconv.Itoa(o.Digits))
+	if o.Type == TOTP {
+		in.add("Period", fmt.Sprintf("%ds", o.Period))
+	} else {
+		in.add("Counter", strconv.Itoa(o.Counter))
+	}
+
+	secret := strings.ToUpper(strings.TrimRight(o.Secret, "="))
+	if key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil {
+		in.warn("secret length is not a whole number of base32 bytes")
+	} else if bits := len(key) * 8; bits < 128 {
+		in.warn("secret is %d bits, RFC 4226 requires at least 128", bits)
+	}
+	if strings.Contains(o.Secret, "=") {
+		in.warn("secret contains base32 padding, which some apps reject")
+	}
+
+	switch o.Algorithm {
+	case "SHA1", "SHA256", "SHA512":
+	default:
+		in.warn("unsupported algorithm %s (use SHA1, SHA256, or SHA512)", o.Algorithm)
+	}
+	if o.Digits != 6 && o.Digits != 8 {
+		in.warn("%d digits, most authenticator apps only support 6 or 8", o.Digits)
+	}
+	if o.Type == TOTP && o.Period != 30 {
+		in.warn("period of %ds, some authenticator apps ignore anything but 30s", o.Period)
+	}
+	if o.Issuer == "" {
+		in.warn("no issuer, authenticator apps will only show the account name")
+	}
+
+	// Label issuer and issuer parameter should agree
+	if u, err := url.Parse(payload); err == nil {
+		label := strings.TrimPrefix(u.Path, "/")
+		if prefix, _, ok := strings.Cut(label, ":"); ok && u.Query().Has("issuer") && prefix != u.Query().Get("issuer") {
+			in.warn("label issuer %q does not match issuer parameter %q", prefix, u.Query().Get("issuer"))
+		}
+	}
+	return nil
+}
+
+func inspectVCard(in *Inspection, payload string) error {
+	v, err := ParseVCard(payload)
+	if err != nil {
+		return err
+	}
+
+	version := ""
+	hasFN, hasN := false, false
+	for _, line := range unfoldLines(payload) {
+		name, _, value, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "VERSION":
+			version = strings.TrimSpace(value)
+		case "FN":
+			hasFN = true
+		case "N":
+			hasN = true
+		}
+	}
+	if version != "" {
+		in.add("Version", version)
+	}
+
+	fields := []Field{
+		{"First name", v.FirstName},
+		{"Last name", v.LastName},
+		{"Organization", v.Organization},
+		{"Title", v.Title},
+		{"Phone", v.Phone},
+		{"Work phone", v.PhoneWork},
+		{"Mobile", v.PhoneMobile},
+		{"Email", v.Email},
+		{"Work email", v.EmailWork},
+		{"Website", v.Website},
+		{"Address", v.Address},
+		{"Note", v.Note},
+	}
+	for _, f := range fields {
+		if f.Value != "" {
+			in.add(f.Name, f.Value)
+		}
+	}
+
+	switch version {
+	case "":
+		in.warn("missing VERSION property")
+	case "2.1", "3.0", "4.0":
+	default:
+		in.warn("unknown vCard version %s", version)
+	}
+	if !hasFN && (version == "3.0" || version == "4.0") {
+		in.warn("missing FN (formatted name), required by vCard %s", version)
+	}
+	if !hasN && version == "3.0" {
+		in.warn("missing N (name), required by vCard 3.0")
+	}
+	for _, email := range []string{v.Email, v.EmailWork} {
+		if email != "" {
+			if _, err := mail.ParseAddress(email); err != nil {
+				in.warn("invalid email address %q", email)
+			}
+		}
+	}
+	return nil
+}
+
+func inspectEvent(in *Inspection, payload string) {
+	names := map[string]string{
+		"SUMMARY":     "Summary",
+		"DTSTART":     "Start",
+		"DTEND":       "End",
+		"LOCATION":    "Location",
+		"DESCRIPTION": "Description",
+		"URL":         "URL",
+		"ORGANIZER":   "Organizer",
+		"TRIGGER":     "Alarm",
+	}
+
+	seen := make(map[string]bool)
+	inAlarm := false
+	for _, line := range unfoldLines(payload) {
+		name, _, value, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		// Only the trigger of an alarm is of interest, not its own
+		// DESCRIPTION or SUMMARY
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VALARM"):
+			inAlarm = true
+		case name == "END" && strings.EqualFold(value, "VALARM"):
+			inAlarm = false
+			continue
+		}
+		if inAlarm && name != "TRIGGER" {
+			continue
+		}
+		seen[name] = true
+		if field, ok := names[name]; ok {
+			// Keep parameters such as TZID visible alongside the date
+			params, _, _ := strings.Cut(line, ":")
+			if _, zone, ok := strings.Cut(params, ";TZID="); ok {
+				zone, _, _ = strings.Cut(zone, ";")
+				value += " (" + zone + ")"
+			}
+			in.add(field, unescapeVCard(value))
+		}
+	}
+
+	if !seen["DTSTART"] {
+		in.warn("missing DTSTART, calendar apps cannot place the event")
+	}
+	if !seen["SUMMARY"] {
+		in.warn("missing SUMMARY, the event will have no title")
+	}
+	if !seen["END"] {
+		in.warn("payload has no END:VEVENT")
+	}
+}
+
+func inspectProxy(in *Inspection, payload string) {
+	scheme, _, _ := strings.Cut(payload, "://")
+	in.add("Protocol", strings.ToLower(scheme))
+
+	fields, problems := parseProxy(payload)
+	in.Fields = append(in.Fields, fields...)
+	for _, problem := range problems {
+		in.warn("%v", problem)
+	}
+}
+
+func inspectEmail(in *Inspection, payload string) error {
+	// Bare addresses are detected as email too
+	if !strings.HasPrefix(strings.ToLower(payload), "mailto:") {
+		payload = "mailto:" + payload
+		in.warn("bare email address, most scanners need a mailto: URL to open a mail client")
+	}
+	e, err := ParseEmail(payload)
+	if err != nil {
+		return err
+	}
+
+	in.add("To", e.To)
+	for _, f := range []Field{{"CC", e.CC}, {"BCC", e.BCC}, {"Subject", e.Subject}, {"Body", e.Body}} {
+		if f.Value != "" {
+			in.add(f.Name, f.Value)
+		}
+	}
+
+	for _, f := range []Field{{"recipient", e.To}, {"CC", e.CC}, {"BCC", e.BCC}} {
+		if f.Value == "" {
+			continue
+		}
+		if _, err := mail.ParseAddressList(f.Value); err != nil {
+			in.warn("invalid %s address %q", f.Name, f.Value)
+		}
+	}
+	return nil
+}
+
+func inspectSMS(in *Inspection, payload string) error {
+	s, err := ParseSMS(payload)
+	if err != nil {
+		return err
+	}
+
+	in.add("Number", s.Number)
+	if s.Body != "" {
+		in.add("Body", s.Body)
+	}
+	checkNumber(in, s.Number)
+	if strings.HasPrefix(strings.ToLower(payload), "smsto:") {
+		in.warn("SMSTO: is a legacy format, sms: URLs are more widely supported")
+	}
+	return nil
+}
+
+func inspectGeo(in *Inspection, payload string) error {
+	g, err := ParseGeo(payload)
+	if err != nil {
+		return err
+	}
+
+	in.add("Latitude", strconv.FormatFloat(g.Latitude, 'f', -1, 64))
+	in.add("Longitude", strconv.FormatFloat(g.Longitude, 'f', -1, 64))
+	if g.Query != "" {
+		in.add("Query", g.Query)
+	}
+	return nil
+}
+
+func inspectPhone(in *Inspection, payload string) {
+	number := payload[len("tel:"):]
+	in.add("Number", number)
+	checkNumber(in, number)
+}
+
+// dialPattern matches the characters allowed in a dialable number
+var dialPattern = regexp.MustCompile(`^\+?[0-9*#()./ -]+$`)
+
+func checkNumber(in *Inspection, number string) {
+	if number == "" {
+		in.warn("phone number is empty")
+	} else if !dialPattern.MatchString(number) {
+		in.warn("phone number %q contains characters that cannot be dialled", number)
+	}
+}
+
+func inspectURL(in *Inspection, payload string) {
+	u, err := url.Parse(payload)
+	if err != nil || u.Scheme == "" {
+		in.add("URL", payload)
+		in.warn("no scheme, scanners may treat this as plain text (add https://)")
+		return
+	}
+
+	in.add("Scheme", u.Scheme)
+	in.add("Host", u.Host)
+	if u.Path != "" {
+		in.add("Path", u.Path)
+	}
+	if u.RawQuery != "" {
+		in.add("Query", u.RawQuery)
+	}
+	if u.Fragment != "" {
+		in.add("Fragment", u.Fragment)
+	}
+
+	if strings.EqualFold(u.Scheme, "http") {
+		in.warn("plain http URL, many phones warn before opening it")
+	}
+	if strings.ContainsAny(payload, " \t") {
+		in.warn("URL contains unencoded whitespace")
+	}
+}
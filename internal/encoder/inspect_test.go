@@ -0,0 +1,261 @@
+package encoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		typ      ContentType
+		fields   map[string]string
+		warnings []string
+	}{
+		{
+			name:    "valid WiFi",
+			payload: "WIFI:T:WPA;S:Home;P:password123;H:true;;",
+			typ:     TypeWiFi,
+			fields:  map[string]string{"SSID": "Home", "Encryption": "WPA", "Password": "password123", "Hidden": "true"},
+		},
+		{
+			name:     "WiFi short passphrase",
+			payload:  "WIFI:T:WPA;S:Home;P:short;H:yes;X:1",
+			typ:      TypeWiFi,
+			fields:   map[string]string{"SSID": "Home", "Hidden": "false"},
+			warnings: []string{"8-63 characters", "hidden flag", `unknown field "X"`, "end with ;;"},
+		},
+		{
+			name:     "WiFi open with password",
+			payload:  "WIFI:T:nopass;S:Cafe;P:secret;;",
+			typ:      TypeWiFi,
+			warnings: []string{"open (nopass) network"},
+		},
+		{
+			name:    "WiFi WPA2-EAP",
+			payload: "WIFI:T:WPA2-EAP;S:Corp;P:secret;E:PEAP;PH2:MSCHAPV2;A:anon;I:alice;;",
+			typ:     TypeWiFi,
+			fields: map[string]string{
+				"Encryption": "WPA2-EAP", "EAP method": "PEAP", "Phase 2": "MSCHAPV2",
+				"Anonymous identity": "anon", "Identity": "alice",
+			},
+		},
+		{
+			name:     "WiFi WPA2-EAP incomplete",
+			payload:  "WIFI:T:WPA2-EAP;S:Corp;P:secret;E:TLS;PH2:GTC;;",
+			typ:      TypeWiFi,
+			warnings: []string{"no phase 2", "no identity"},
+		},
+		{
+			name:     "WiFi WPA3 with stray EAP fields",
+			payload:  "WIFI:T:SAE;R:1;S:Office;P:short;I:bob;;",
+			typ:      TypeWiFi,
+			fields:   map[string]string{"Encryption": "SAE", "Transition disable": "WPA3 only once joined"},
+			warnings: []string{"at least 8 characters", "EAP fields"},
+		},
+		{
+			name:     "WiFi bad WEP key",
+			payload:  "WIFI:T:WEP;S:Old;P:abcdef;;",
+			typ:      TypeWiFi,
+			warnings: []string{"WEP key"},
+		},
+		{
+			name:    "DPP URI",
+			payload: "DPP:C:81/1,115/36;M:5254005828e5;V:2;K:MDkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDIgADURzxmttZoIRIPWGoQMV00XHWCAQIhXruVWOz0NjlkIA=;;",
+			typ:     TypeDPP,
+			fields:  map[string]string{"Channels": "81/1, 115/36", "MAC": "5254005828e5", "Version": "2"},
+		},
+		{
+			name:     "DPP bad channel",
+			payload:  "DPP:C:channel6;K:MDkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDIgADURzxmttZoIRIPWGoQMV00XHWCAQIhXruVWOz0NjlkIA=;",
+			typ:      TypeDPP,
+			warnings: []string{"invalid DPP channel", "end with ;;"},
+		},
+		{
+			name:    "valid TOTP",
+			payload: "otpauth://totp/Acme:bob@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Acme",
+			typ:     TypeOTP,
+			fields: map[string]string{
+				"Type": "totp", "Issuer": "Acme", "Account": "bob@example.com",
+				"Algorithm": "SHA1", "Digits": "6", "Period": "30s",
+			},
+		},
+		{
+			name:     "weak TOTP",
+			payload:  "otpauth://totp/ACME:bob?secret=JBSWY3DPEHPK3PXP&issuer=Acme&digits=7&period=60&algorithm=MD5",
+			typ:      TypeOTP,
+			fields:   map[string]string{"Digits": "7", "Period": "60s"},
+			warnings: []string{"80 bits", "unsupported algorithm MD5", "7 digits", "period of 60s", "does not match"},
+		},
+		{
+			name:     "HOTP without issuer",
+			payload:  "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&counter=5",
+			typ:      TypeOTP,
+			fields:   map[string]string{"Type": "hotp", "Counter": "5"},
+			warnings: []string{"no issuer"},
+		},
+		{
+			name:    "valid vCard",
+			payload: "BEGIN:VCARD\nVERSION:3.0\nN:Doe;John;;;\nFN:John Doe\nORG:Acme\nTEL;TYPE=CELL:+1 555 0100\nEMAIL:john@example.com\nEND:VCARD",
+			typ:     TypeVCard,
+			fields: map[string]string{
+				"Version": "3.0", "First name": "John", "Last name": "Doe",
+				"Organization": "Acme", "Mobile": "+1 555 0100", "Email": "john@example.com",
+			},
+		},
+		{
+			name:     "vCard missing properties",
+			payload:  "BEGIN:VCARD\nVERSION:3.0\nORG:Acme\nEMAIL:not-an-email\nEND:VCARD",
+			typ:      TypeVCard,
+			warnings: []string{"missing FN", "missing N", "invalid email"},
+		},
+		{
+			name:     "vCard without version",
+			payload:  "BEGIN:VCARD\nFN:Jane\nEND:VCARD",
+			typ:      TypeVCard,
+			fields:   map[string]string{"First name": "Jane"},
+			warnings: []string{"missing VERSION"},
+		},
+		{
+			name:    "event",
+			payload: "BEGIN:VEVENT\r\nSUMMARY:Meetup\\, Berlin\r\nDTSTART;TZID=Europe/Berlin:20261017T183000\r\nEND:VEVENT",
+			typ:     TypeEvent,
+			fields:  map[string]string{"Summary": "Meetup, Berlin", "Start": "20261017T183000 (Europe/Berlin)"},
+		},
+		{
+			name:     "event without start",
+			payload:  "BEGIN:VEVENT\nSUMMARY:Someday",
+			typ:      TypeEvent,
+			warnings: []string{"missing DTSTART", "END:VEVENT"},
+		},
+		{
+			name:    "proxy",
+			payload: "trojan://secret@example.com:443?security=tls#US",
+			typ:     TypeProxy,
+			fields:  map[string]string{"Protocol": "trojan", "Name": "US", "Server": "example.com", "Port": "443", "Password": "secret"},
+		},
+		{
+			name:     "truncated proxy",
+			payload:  "vless://" + testUUID + "@example.com",
+			typ:      TypeProxy,
+			warnings: []string{"missing port"},
+		},
+		{
+			name:    "email",
+			payload: "mailto:team@example.com?subject=Hello",
+			typ:     TypeEmail,
+			fields:  map[string]string{"To": "team@example.com", "Subject": "Hello"},
+		},
+		{
+			name:     "bare email",
+			payload:  "team@example.com",
+			typ:      TypeEmail,
+			fields:   map[string]string{"To": "team@example.com"},
+			warnings: []string{"mailto:"},
+		},
+		{
+			name:     "legacy SMS",
+			payload:  "SMSTO:+15550100:Hi there",
+			typ:      TypeSMS,
+			fields:   map[string]string{"Number": "+15550100", "Body": "Hi there"},
+			warnings: []string{"legacy"},
+		},
+		{
+			name:    "geo",
+			payload: "geo:48.8584,2.2945?q=Eiffel+Tower",
+			typ:     TypeGeo,
+			fields:  map[string]string{"Latitude": "48.8584", "Longitude": "2.2945", "Query": "Eiffel Tower"},
+		},
+		{
+			name:     "phone",
+			payload:  "tel:+1-555-CALL",
+			typ:      TypePhone,
+			fields:   map[string]string{"Number": "+1-555-CALL"},
+			warnings: []string{"cannot be dialled"},
+		},
+		{
+			name:    "https URL",
+			payload: "https://example.com/path?q=1#top",
+			typ:     TypeURL,
+			fields:  map[string]string{"Scheme": "https", "Host": "example.com", "Path": "/path", "Query": "q=1", "Fragment": "top"},
+		},
+		{
+			name:     "http URL",
+			payload:  "http://example.com",
+			typ:      TypeURL,
+			warnings: []string{"plain http"},
+		},
+		{
+			name:    "text",
+			payload: "Hello, 世界",
+			typ:     TypeText,
+			fields:  map[string]string{"Length": "9 characters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := Inspect(tt.payload)
+			if err != nil {
+				t.Fatalf("Inspect(%q) unexpected error: %v", tt.payload, err)
+			}
+			if in.Type != tt.typ {
+				t.Errorf("Inspect(%q) type = %q, want %q", tt.payload, in.Type, tt.typ)
+			}
+
+			fields := make(map[string]string)
+			for _, f := range in.Fields {
+				fields[f.Name] = f.Value
+			}
+			for name, want := range tt.fields {
+				if got, ok := fields[name]; !ok || got != want {
+					t.Errorf("Inspect(%q) field %s = %q, want %q", tt.payload, name, got, want)
+				}
+			}
+
+			if len(in.Warnings) != len(tt.warnings) {
+				t.Fatalf("Inspect(%q) warnings = %q, want %d", tt.payload, in.Warnings, len(tt.warnings))
+			}
+			for i, want := range tt.warnings {
+				if !strings.Contains(in.Warnings[i], want) {
+					t.Errorf("Inspect(%q) warning %d = %q, want it to mention %q", tt.payload, i, in.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectInvalid(t *testing.T) {
+	tests := []string{
+		"WIFI:T:WPA;P:nossid;;",
+		"otpauth://totp/Acme?issuer=Acme",
+		"geo:91,0",
+	}
+
+	for _, payload := range tests {
+		t.Run(payload, func(t *testing.T) {
+			if _, err := Inspect(payload); err == nil {
+				t.Errorf("Inspect(%q) expected error, got nil", payload)
+			}
+		})
+	}
+}
+
+func TestInspectEncoded(t *testing.T) {
+	// Payloads produced by the encoders should inspect cleanly
+	payloads := []string{
+		(&WiFi{SSID: "Home", Password: "password123"}).Encode(),
+		(&VCard{FirstName: "John", LastName: "Doe", Email: "john@example.com"}).Encode(),
+	}
+
+	for _, payload := range payloads {
+		in, err := Inspect(payload)
+		if err != nil {
+			t.Fatalf("Inspect(%q) unexpected error: %v", payload, err)
+		}
+		if len(in.Warnings) != 0 {
+			t.Errorf("Inspect(%q) warnings = %q, want none", payload, in.Warnings)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -85,3 +86,79 @@ func (o *OTP) Encode() string {
 
 	return fmt.Sprintf("otpauth://%s/%s?%s", otpType, label, strings.Join(params, "&"))
 }
+
+// ParseOTP parses an otpauth:// URL back into an OTP configuration.
+// Parameters left out of the URL are filled in with their defaults.
+func ParseOTP(s string) (*OTP, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URL: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, "otpauth") {
+		return nil, fmt.Errorf("not an OTP payload: scheme is %q, want otpauth", u.Scheme)
+	}
+
+	o := &OTP{Type: OTPType(strings.ToLower(u.Host))}
+	if o.Type != TOTP && o.Type != HOTP {
+		return nil, fmt.Errorf("unknown OTP type: %s (use totp or hotp)", u.Host)
+	}
+
+	// Label is "Issuer:Account" or just "Account"
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		o.Issuer = issuer
+		o.Account = strings.TrimSpace(account)
+	} else {
+		o.Account = label
+	}
+
+	query := u.Query()
+	o.Secret = query.Get("secret")
+	if o.Secret == "" {
+		return nil, fmt.Errorf("OTP payload has no secret")
+	}
+	if err := ValidateSecret(o.Secret); err != nil {
+		return nil, err
+	}
+
+	// The issuer parameter takes precedence over the label prefix
+	if issuer := query.Get("issuer"); issuer != "" {
+		o.Issuer = issuer
+	}
+
+	o.Algorithm = "SHA1"
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		o.Algorithm = strings.ToUpper(algorithm)
+	}
+
+	numbers := []struct {
+		name  string
+		value *int
+		def   int
+	}{
+		{"digits", &o.Digits, 6},
+		{"period", &o.Period, 30},
+		{"counter", &o.Counter, 0},
+	}
+	for _, n := range numbers {
+		*n.value = n.def
+		raw := query.Get(n.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid OTP %s: %s", n.name, raw)
+		}
+		*n.value = v
+	}
+
+	if o.Type == HOTP {
+		o.Period = 0
+		if !query.Has("counter") {
+			return nil, fmt.Errorf("hotp payload has no counter")
+		}
+	}
+
+	return o, nil
+}
@@ -144,3 +144,72 @@ func TestValidateSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestParseOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected OTP
+		hasError bool
+	}{
+		{
+			name:     "full TOTP",
+			input:    "otpauth://totp/GitHub:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=GitHub&algorithm=SHA256&digits=8&period=60",
+			expected: OTP{Type: TOTP, Secret: "JBSWY3DPEHPK3PXP", Issuer: "GitHub", Account: "user@example.com", Algorithm: "SHA256", Digits: 8, Period: 60},
+		},
+		{
+			name:     "defaults filled in",
+			input:    "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP",
+			expected: OTP{Type: TOTP, Secret: "JBSWY3DPEHPK3PXP", Account: "alice", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name:     "issuer parameter wins over label",
+			input:    "otpauth://totp/Old%20Name:%20bob?secret=JBSWY3DPEHPK3PXP&issuer=New+Name",
+			expected: OTP{Type: TOTP, Secret: "JBSWY3DPEHPK3PXP", Issuer: "New Name", Account: "bob", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name:     "HOTP",
+			input:    "otpauth://hotp/Service:alice?secret=JBSWY3DPEHPK3PXP&counter=42",
+			expected: OTP{Type: HOTP, Secret: "JBSWY3DPEHPK3PXP", Issuer: "Service", Account: "alice", Algorithm: "SHA1", Digits: 6, Counter: 42},
+		},
+		{name: "missing secret", input: "otpauth://totp/alice?issuer=X", hasError: true},
+		{name: "invalid secret", input: "otpauth://totp/alice?secret=not-base32!", hasError: true},
+		{name: "unknown type", input: "otpauth://motp/alice?secret=JBSWY3DPEHPK3PXP", hasError: true},
+		{name: "HOTP without counter", input: "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP", hasError: true},
+		{name: "bad digits", input: "otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=six", hasError: true},
+		{name: "wrong scheme", input: "https://example.com/?secret=JBSWY3DPEHPK3PXP", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseOTP(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseOTP(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOTP(%q) unexpected error: %v", tt.input, err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseOTP(%q) = %+v, want %+v", tt.input, *result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseOTPRoundTrip(t *testing.T) {
+	original := OTP{Type: TOTP, Secret: "JBSWY3DPEHPK3PXP", Issuer: "ACME Co", Account: "jane.doe", Algorithm: "SHA512", Digits: 8, Period: 30}
+	encoded := original.Encode()
+	parsed, err := ParseOTP(encoded)
+	if err != nil {
+		t.Fatalf("ParseOTP(%q) unexpected error: %v", encoded, err)
+	}
+	if *parsed != original {
+		t.Errorf("ParseOTP(%q) = %+v, want %+v", encoded, *parsed, original)
+	}
+	if again := parsed.Encode(); again != encoded {
+		t.Errorf("re-encoded = %q, want %q", again, encoded)
+	}
+}
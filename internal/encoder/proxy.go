@@ -0,0 +1,275 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProxyTransport holds the transport and TLS settings shared by the
+// VMess, VLESS and Trojan share links
+type ProxyTransport struct {
+	Network       string // tcp, ws, grpc, h2, httpupgrade, xhttp, kcp, quic (default: tcp)
+	HeaderType    string // Header obfuscation for tcp/kcp/quic, e.g. http (default: none)
+	Host          string // HTTP Host header
+	Path          string // ws/h2/httpupgrade/xhttp path
+	ServiceName   string // gRPC service name
+	Security      string // none, tls, reality
+	SNI           string
+	ALPN          string // Comma-separated, e.g. h2,http/1.1
+	Fingerprint   string // uTLS fingerprint, e.g. chrome
+	AllowInsecure bool   // Skip certificate verification
+	PublicKey     string // REALITY public key
+	ShortID       string // REALITY short ID
+}
+
+// query returns the transport as share link query parameters, in the
+// form used by Xray's VLESS/Trojan share link proposal
+func (t *ProxyTransport) query(q url.Values) {
+	network := t.Network
+	if network == "" {
+		network = "tcp"
+	}
+	q.Set("type", network)
+	if t.HeaderType != "" && t.HeaderType != "none" {
+		q.Set("headerType", t.HeaderType)
+	}
+	setNonEmpty(q, "host", t.Host)
+	setNonEmpty(q, "path", t.Path)
+	setNonEmpty(q, "serviceName", t.ServiceName)
+	setNonEmpty(q, "security", t.Security)
+	setNonEmpty(q, "sni", t.SNI)
+	setNonEmpty(q, "alpn", t.ALPN)
+	setNonEmpty(q, "fp", t.Fingerprint)
+	setNonEmpty(q, "pbk", t.PublicKey)
+	setNonEmpty(q, "sid", t.ShortID)
+	if t.AllowInsecure {
+		q.Set("allowInsecure", "1")
+	}
+}
+
+func setNonEmpty(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+// shareLink assembles scheme://userinfo@host:port[path]?query#name
+func shareLink(scheme string, user *url.Userinfo, address string, port int, path string, q url.Values, name string) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     user,
+		Host:     net.JoinHostPort(address, strconv.Itoa(port)),
+		Path:     path,
+		RawQuery: q.Encode(),
+		Fragment: name,
+	}
+	return u.String()
+}
+
+// VMess encodes a VMess server as a v2rayN style share link
+// (vmess:// followed by base64 JSON)
+type VMess struct {
+	Name      string
+	Address   string
+	Port      int
+	ID        string // UUID
+	AlterID   int
+	Cipher    string // auto, aes-128-gcm, chacha20-poly1305, none, zero (default: auto)
+	Transport ProxyTransport
+}
+
+// vmessJSON is the v2rayN share link format, version 2. Numbers are
+// written as strings as v2rayN does.
+type vmessJSON struct {
+	V    string `json:"v"`
+	PS   string `json:"ps"`
+	Add  string `json:"add"`
+	Port string `json:"port"`
+	ID   string `json:"id"`
+	Aid  string `json:"aid"`
+	Scy  string `json:"scy"`
+	Net  string `json:"net"`
+	Type string `json:"type"`
+	Host string `json:"host"`
+	Path string `json:"path"`
+	TLS  string `json:"tls"`
+	SNI  string `json:"sni"`
+	ALPN string `json:"alpn"`
+	FP   string `json:"fp"`
+}
+
+// Encode returns the vmess:// share link
+func (v *VMess) Encode() string {
+	t := v.Transport
+	cipher := v.Cipher
+	if cipher == "" {
+		cipher = "auto"
+	}
+	network := t.Network
+	if network == "" {
+		network = "tcp"
+	}
+	headerType := t.HeaderType
+	if headerType == "" {
+		headerType = "none"
+	}
+	path := t.Path
+	if network == "grpc" {
+		// v2rayN carries the gRPC service name in path
+		path = t.ServiceName
+	}
+	security := t.Security
+	if security == "none" {
+		security = ""
+	}
+
+	data, _ := json.Marshal(vmessJSON{
+		V:    "2",
+		PS:   v.Name,
+		Add:  v.Address,
+		Port: strconv.Itoa(v.Port),
+		ID:   v.ID,
+		Aid:  strconv.Itoa(v.AlterID),
+		Scy:  cipher,
+		Net:  network,
+		Type: headerType,
+		Host: t.Host,
+		Path: path,
+		TLS:  security,
+		SNI:  t.SNI,
+		ALPN: t.ALPN,
+		FP:   t.Fingerprint,
+	})
+	return "vmess://" + base64.StdEncoding.EncodeToString(data)
+}
+
+// VLESS encodes a VLESS server share link
+type VLESS struct {
+	Name      string
+	Address   string
+	Port      int
+	UUID      string
+	Flow      string // e.g. xtls-rprx-vision
+	Transport ProxyTransport
+}
+
+// Encode returns the vless:// share link
+func (v *VLESS) Encode() string {
+	q := url.Values{}
+	q.Set("encryption", "none")
+	setNonEmpty(q, "flow", v.Flow)
+	v.Transport.query(q)
+	return shareLink("vless", url.User(v.UUID), v.Address, v.Port, "", q, v.Name)
+}
+
+// Trojan encodes a Trojan server share link
+type Trojan struct {
+	Name      string
+	Address   string
+	Port      int
+	Password  string
+	Transport ProxyTransport // Security defaults to tls
+}
+
+// Encode returns the trojan:// share link
+func (t *Trojan) Encode() string {
+	transport := t.Transport
+	if transport.Security == "" {
+		transport.Security = "tls"
+	}
+	q := url.Values{}
+	transport.query(q)
+	return shareLink("trojan", url.User(t.Password), t.Address, t.Port, "", q, t.Name)
+}
+
+// Shadowsocks encodes a Shadowsocks server as a SIP002 share link
+type Shadowsocks struct {
+	Name     string
+	Address  string
+	Port     int
+	Method   string // e.g. aes-256-gcm, chacha20-ietf-poly1305, 2022-blake3-aes-128-gcm
+	Password string
+	Plugin   string // Plugin and options, e.g. "obfs-local;obfs=http;obfs-host=example.com"
+}
+
+// Encode returns the ss:// share link. The user info is base64url encoded,
+// except for Shadowsocks 2022 methods where SIP002 requires it percent
+// encoded.
+func (s *Shadowsocks) Encode() string {
+	var user *url.Userinfo
+	if strings.HasPrefix(s.Method, "2022-") {
+		user = url.UserPassword(s.Method, s.Password)
+	} else {
+		user = url.User(base64.RawURLEncoding.EncodeToString([]byte(s.Method + ":" + s.Password)))
+	}
+
+	q := url.Values{}
+	path := ""
+	if s.Plugin != "" {
+		q.Set("plugin", s.Plugin)
+		path = "/"
+	}
+	return shareLink("ss", user, s.Address, s.Port, path, q, s.Name)
+}
+
+// Hysteria2 encodes a Hysteria 2 server share link
+type Hysteria2 struct {
+	Name         string
+	Address      string
+	Port         int
+	Password     string // Authentication password
+	SNI          string
+	Insecure     bool   // Skip certificate verification
+	Obfs         string // Obfuscation type, e.g. salamander
+	ObfsPassword string
+	PinSHA256    string // Certificate fingerprint to pin
+}
+
+// Encode returns the hysteria2:// share link
+func (h *Hysteria2) Encode() string {
+	q := url.Values{}
+	setNonEmpty(q, "sni", h.SNI)
+	if h.Insecure {
+		q.Set("insecure", "1")
+	}
+	setNonEmpty(q, "obfs", h.Obfs)
+	setNonEmpty(q, "obfs-password", h.ObfsPassword)
+	setNonEmpty(q, "pinSHA256", h.PinSHA256)
+
+	var user *url.Userinfo
+	if h.Password != "" {
+		user = url.User(h.Password)
+	}
+	return shareLink("hysteria2", user, h.Address, h.Port, "/", q, h.Name)
+}
+
+// TUIC encodes a TUIC v5 server share link
+type TUIC struct {
+	Name              string
+	Address           string
+	Port              int
+	UUID              string
+	Password          string
+	CongestionControl string // bbr, cubic, new_reno
+	UDPRelayMode      string // native, quic
+	ALPN              string // Comma-separated, e.g. h3
+	SNI               string
+	AllowInsecure     bool
+}
+
+// Encode returns the tuic:// share link
+func (t *TUIC) Encode() string {
+	q := url.Values{}
+	setNonEmpty(q, "congestion_control", t.CongestionControl)
+	setNonEmpty(q, "udp_relay_mode", t.UDPRelayMode)
+	setNonEmpty(q, "alpn", t.ALPN)
+	setNonEmpty(q, "sni", t.SNI)
+	if t.AllowInsecure {
+		q.Set("allow_insecure", "1")
+	}
+	return shareLink("tuic", url.UserPassword(t.UUID, t.Password), t.Address, t.Port, "", q, t.Name)
+}
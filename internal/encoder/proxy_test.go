@@ -0,0 +1,178 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testUUID = "b831381d-6324-4d53-ad4f-8cda48b30811"
+
+func TestVMessEncode(t *testing.T) {
+	v := VMess{
+		Name:    "Tokyo 01",
+		Address: "example.com",
+		Port:    443,
+		ID:      testUUID,
+		Transport: ProxyTransport{
+			Network:  "ws",
+			Host:     "cdn.example.com",
+			Path:     "/ray",
+			Security: "tls",
+			SNI:      "cdn.example.com",
+		},
+	}
+
+	result := v.Encode()
+	if !strings.HasPrefix(result, "vmess://") {
+		t.Fatalf("Encode() = %q, want vmess:// prefix", result)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(result, "vmess://"))
+	if err != nil {
+		t.Fatalf("Encode() payload is not base64: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Encode() payload is not JSON: %v", err)
+	}
+	want := map[string]string{
+		"v": "2", "ps": "Tokyo 01", "add": "example.com", "port": "443", "id": testUUID,
+		"aid": "0", "scy": "auto", "net": "ws", "type": "none", "host": "cdn.example.com",
+		"path": "/ray", "tls": "tls", "sni": "cdn.example.com", "alpn": "", "fp": "",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Encode() %s = %q, want %q", key, got[key], value)
+		}
+	}
+}
+
+func TestVMessEncodeGRPC(t *testing.T) {
+	v := VMess{Address: "1.2.3.4", Port: 8443, ID: testUUID, Transport: ProxyTransport{Network: "grpc", ServiceName: "svc"}}
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(v.Encode(), "vmess://"))
+
+	var got map[string]string
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Encode() payload is not JSON: %v", err)
+	}
+	if got["path"] != "svc" || got["net"] != "grpc" {
+		t.Errorf("Encode() net = %q, path = %q, want grpc, svc", got["net"], got["path"])
+	}
+}
+
+func TestProxyEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		expected string
+	}{
+		{
+			name: "vless reality",
+			encoder: &VLESS{
+				Name:    "HK Reality",
+				Address: "example.com",
+				Port:    443,
+				UUID:    testUUID,
+				Flow:    "xtls-rprx-vision",
+				Transport: ProxyTransport{
+					Security:    "reality",
+					SNI:         "www.microsoft.com",
+					Fingerprint: "chrome",
+					PublicKey:   "jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0",
+					ShortID:     "0123abcd",
+				},
+			},
+			expected: "vless://" + testUUID + "@example.com:443?encryption=none&flow=xtls-rprx-vision&fp=chrome" +
+				"&pbk=jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0&security=reality&sid=0123abcd" +
+				"&sni=www.microsoft.com&type=tcp#HK%20Reality",
+		},
+		{
+			name: "vless ws over IPv6",
+			encoder: &VLESS{
+				Address:   "2001:db8::1",
+				Port:      8080,
+				UUID:      testUUID,
+				Transport: ProxyTransport{Network: "ws", Path: "/ws?ed=2048"},
+			},
+			expected: "vless://" + testUUID + "@[2001:db8::1]:8080?encryption=none&path=%2Fws%3Fed%3D2048&type=ws",
+		},
+		{
+			name: "trojan defaults to tls",
+			encoder: &Trojan{
+				Name:      "US",
+				Address:   "example.com",
+				Port:      443,
+				Password:  "p@ss:word",
+				Transport: ProxyTransport{SNI: "example.com", AllowInsecure: true},
+			},
+			expected: "trojan://p%40ss%3Aword@example.com:443?allowInsecure=1&security=tls&sni=example.com&type=tcp#US",
+		},
+		{
+			name:     "shadowsocks",
+			encoder:  &Shadowsocks{Name: "SS", Address: "192.168.100.1", Port: 8888, Method: "aes-128-gcm", Password: "test"},
+			expected: "ss://YWVzLTEyOC1nY206dGVzdA@192.168.100.1:8888#SS",
+		},
+		{
+			name: "shadowsocks with plugin",
+			encoder: &Shadowsocks{
+				Address:  "192.168.100.1",
+				Port:     8888,
+				Method:   "rc4-md5",
+				Password: "passwd",
+				Plugin:   "obfs-local;obfs=http",
+			},
+			expected: "ss://cmM0LW1kNTpwYXNzd2Q@192.168.100.1:8888/?plugin=obfs-local%3Bobfs%3Dhttp",
+		},
+		{
+			name: "shadowsocks 2022",
+			encoder: &Shadowsocks{
+				Address:  "example.com",
+				Port:     443,
+				Method:   "2022-blake3-aes-256-gcm",
+				Password: "YctPZ6U7xPPcU+gp3u+0tx/tRizJN9K8y+uKlW2qjlI=",
+			},
+			expected: "ss://2022-blake3-aes-256-gcm:YctPZ6U7xPPcU+gp3u+0tx%2FtRizJN9K8y+uKlW2qjlI=@example.com:443",
+		},
+		{
+			name: "hysteria2",
+			encoder: &Hysteria2{
+				Name:         "hy2",
+				Address:      "example.com",
+				Port:         443,
+				Password:     "letmein",
+				SNI:          "real.example.com",
+				Insecure:     true,
+				Obfs:         "salamander",
+				ObfsPassword: "gawrgura",
+			},
+			expected: "hysteria2://letmein@example.com:443/?insecure=1&obfs=salamander&obfs-password=gawrgura&sni=real.example.com#hy2",
+		},
+		{
+			name: "tuic",
+			encoder: &TUIC{
+				Address:           "example.com",
+				Port:              443,
+				UUID:              testUUID,
+				Password:          "secret",
+				CongestionControl: "bbr",
+				UDPRelayMode:      "native",
+				ALPN:              "h3",
+			},
+			expected: "tuic://" + testUUID + ":secret@example.com:443?alpn=h3&congestion_control=bbr&udp_relay_mode=native",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.encoder.Encode()
+			if result != tt.expected {
+				t.Errorf("Encode() = %q, want %q", result, tt.expected)
+			}
+			if contentType, err := Detect(result); contentType != TypeProxy || err != nil {
+				t.Errorf("Detect(Encode()) = %q, %v, want %q", contentType, err, TypeProxy)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SubscriptionNode is one proxy server of a subscription, as a share link
+type SubscriptionNode struct {
+	Name string
+	Link string
+}
+
+// ParseSubscription converts a proxy subscription into share links. It
+// accepts a Clash YAML config, a sing-box JSON config, or a list of share
+// links, plain or base64 encoded as subscription servers deliver them.
+// Nodes that cannot be expressed as a share link (unsupported protocols or
+// plugins) are skipped and described in skipped; groups, direct and block
+// outbounds are ignored.
+func ParseSubscription(data []byte) (nodes []SubscriptionNode, skipped []string, err error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("subscription is empty")
+	}
+
+	// Structured configs come first: their comments and values may hold
+	// URLs that would pass for share links
+	if data[0] == '{' {
+		return parseSingBox(data)
+	}
+	var probe map[string]any
+	if yaml.Unmarshal(data, &probe) == nil {
+		if _, ok := probe["proxies"]; ok {
+			return parseClash(data)
+		}
+	}
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(firstLine, []byte("://")) {
+		nodes, err = parseLinkList(string(data))
+		return nodes, nil, err
+	}
+
+	// Subscription servers base64 encode the link list
+	if decoded, err := decodeBase64(strings.Join(strings.Fields(string(data)), "")); err == nil && bytes.Contains(decoded, []byte("://")) {
+		nodes, err = parseLinkList(string(decoded))
+		return nodes, nil, err
+	}
+	return nil, nil, fmt.Errorf("unrecognized subscription format (want Clash YAML, sing-box JSON or share links)")
+}
+
+// parseLinkList reads one share link per line, taking names from the links
+func parseLinkList(s string) ([]SubscriptionNode, error) {
+	var nodes []SubscriptionNode
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if t, _ := Detect(line); t != TypeProxy {
+			return nil, fmt.Errorf("not a proxy share link: %s", line)
+		}
+
+		node := SubscriptionNode{Link: line}
+		fields, _ := parseProxy(line)
+		for _, f := range fields {
+			if f.Name == "Name" {
+				node.Name = f.Value
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("subscription contains no share links")
+	}
+	return nodes, nil
+}
+
+// clashProxy is an entry of the proxies list of a Clash (Mihomo) config
+type clashProxy struct {
+	Name           string         `yaml:"name"`
+	Type           string         `yaml:"type"`
+	Server         string         `yaml:"server"`
+	Port           int            `yaml:"port"`
+	UUID           string         `yaml:"uuid"`
+	AlterID        int            `yaml:"alterId"`
+	Cipher         string         `yaml:"cipher"`
+	Password       string         `yaml:"password"`
+	Flow           string         `yaml:"flow"`
+	Network        string         `yaml:"network"`
+	TLS            bool           `yaml:"tls"`
+	ServerName     string         `yaml:"servername"`
+	SNI            string         `yaml:"sni"`
+	ALPN           []string       `yaml:"alpn"`
+	SkipCertVerify bool           `yaml:"skip-cert-verify"`
+	Fingerprint    string         `yaml:"client-fingerprint"`
+	Plugin         string         `yaml:"plugin"`
+	PluginOpts     map[string]any `yaml:"plugin-opts"`
+	Obfs           string         `yaml:"obfs"`
+	ObfsPassword   string         `yaml:"obfs-password"`
+	Congestion     string         `yaml:"congestion-controller"`
+	UDPRelayMode   string         `yaml:"udp-relay-mode"`
+	WSOpts         struct {
+		Path    string            `yaml:"path"`
+		Headers map[string]string `yaml:"headers"`
+	} `yaml:"ws-opts"`
+	H2Opts struct {
+		Host []string `yaml:"host"`
+		Path string   `yaml:"path"`
+	} `yaml:"h2-opts"`
+	GRPCOpts struct {
+		ServiceName string `yaml:"grpc-service-name"`
+	} `yaml:"grpc-opts"`
+	RealityOpts struct {
+		PublicKey string `yaml:"public-key"`
+		ShortID   string `yaml:"short-id"`
+	} `yaml:"reality-opts"`
+}
+
+func parseClash(data []byte) ([]SubscriptionNode, []string, error) {
+	var config struct {
+		Proxies []clashProxy `yaml:"proxies"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Clash config: %w", err)
+	}
+
+	var nodes []SubscriptionNode
+	var skipped []string
+	for _, p := range config.Proxies {
+		link, err := p.encoder()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", p.Name, err))
+			continue
+		}
+		nodes = append(nodes, SubscriptionNode{Name: p.Name, Link: link.Encode()})
+	}
+	if len(nodes) == 0 && len(skipped) == 0 {
+		return nil, nil, fmt.Errorf("Clash config has no proxies")
+	}
+	return nodes, skipped, nil
+}
+
+func (p *clashProxy) encoder() (Encoder, error) {
+	switch p.Type {
+	case "vmess":
+		return &VMess{Name: p.Name, Address: p.Server, Port: p.Port, ID: p.UUID, AlterID: p.AlterID, Cipher: p.Cipher, Transport: p.transport()}, nil
+	case "vless":
+		return &VLESS{Name: p.Name, Address: p.Server, Port: p.Port, UUID: p.UUID, Flow: p.Flow, Transport: p.transport()}, nil
+	case "trojan":
+		return &Trojan{Name: p.Name, Address: p.Server, Port: p.Port, Password: p.Password, Transport: p.transport()}, nil
+	case "ss":
+		plugin, err := p.sip003Plugin()
+		if err != nil {
+			return nil, err
+		}
+		return &Shadowsocks{Name: p.Name, Address: p.Server, Port: p.Port, Method: p.Cipher, Password: p.Password, Plugin: plugin}, nil
+	case "hysteria2":
+		return &Hysteria2{
+			Name: p.Name, Address: p.Server, Port: p.Port, Password: p.Password, SNI: p.SNI,
+			Insecure: p.SkipCertVerify, Obfs: p.Obfs, ObfsPassword: p.ObfsPassword,
+		}, nil
+	case "tuic":
+		return &TUIC{
+			Name: p.Name, Address: p.Server, Port: p.Port, UUID: p.UUID, Password: p.Password,
+			CongestionControl: p.Congestion, UDPRelayMode: p.UDPRelayMode,
+			ALPN: strings.Join(p.ALPN, ","), SNI: p.SNI, AllowInsecure: p.SkipCertVerify,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy type %q", p.Type)
+}
+
+func (p *clashProxy) transport() ProxyTransport {
+	t := ProxyTransport{
+		Network:       p.Network,
+		SNI:           p.ServerName,
+		ALPN:          strings.Join(p.ALPN, ","),
+		Fingerprint:   p.Fingerprint,
+		AllowInsecure: p.SkipCertVerify,
+	}
+	if p.Type == "trojan" {
+		// Trojan always uses TLS and names the server sni
+		t.SNI = p.SNI
+	}
+	if p.TLS {
+		t.Security = "tls"
+	}
+	if p.RealityOpts.PublicKey != "" {
+		t.Security = "reality"
+		t.PublicKey = p.RealityOpts.PublicKey
+		t.ShortID = p.RealityOpts.ShortID
+	}
+
+	switch p.Network {
+	case "ws":
+		t.Path = p.WSOpts.Path
+		t.Host = p.WSOpts.Headers["Host"]
+	case "h2":
+		t.Path = p.H2Opts.Path
+		t.Host = strings.Join(p.H2Opts.Host, ",")
+	case "grpc":
+		t.ServiceName = p.GRPCOpts.ServiceName
+	case "http":
+		// Clash's http network is plain TCP with an HTTP header
+		t.Network = "tcp"
+		t.HeaderType = "http"
+	}
+	return t
+}
+
+// sip003Plugin converts Clash plugin options into a SIP003 plugin string
+func (p *clashProxy) sip003Plugin() (string, error) {
+	opt := func(key string) string {
+		if v, ok := p.PluginOpts[key]; ok {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+
+	switch p.Plugin {
+	case "":
+		return "", nil
+	case "obfs":
+		plugin := "obfs-local;obfs=" + opt("mode")
+		if host := opt("host"); host != "" {
+			plugin += ";obfs-host=" + host
+		}
+		return plugin, nil
+	case "v2ray-plugin":
+		plugin := "v2ray-plugin"
+		if mode := opt("mode"); mode != "" && mode != "websocket" {
+			plugin += ";mode=" + mode
+		}
+		if opt("tls") == "true" {
+			plugin += ";tls"
+		}
+		for _, key := range []string{"host", "path"} {
+			if v := opt(key); v != "" {
+				plugin += ";" + key + "=" + v
+			}
+		}
+		return plugin, nil
+	}
+	return "", fmt.Errorf("unsupported ss plugin %q", p.Plugin)
+}
+
+// listable decodes a sing-box field that may be a string or a list
+type listable []string
+
+func (l *listable) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = listable{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// singBoxOutbound is an entry of the outbounds list of a sing-box config
+type singBoxOutbound struct {
+	Type         string `json:"type"`
+	Tag          string `json:"tag"`
+	Server       string `json:"server"`
+	ServerPort   int    `json:"server_port"`
+	UUID         string `json:"uuid"`
+	AlterID      int    `json:"alter_id"`
+	Security     string `json:"security"`
+	Password     string `json:"password"`
+	Method       string `json:"method"`
+	Flow         string `json:"flow"`
+	Plugin       string `json:"plugin"`
+	PluginOpts   string `json:"plugin_opts"`
+	Congestion   string `json:"congestion_control"`
+	UDPRelayMode string `json:"udp_relay_mode"`
+	TLS          struct {
+		Enabled    bool     `json:"enabled"`
+		ServerName string   `json:"server_name"`
+		Insecure   bool     `json:"insecure"`
+		ALPN       listable `json:"alpn"`
+		UTLS       struct {
+			Enabled     bool   `json:"enabled"`
+			Fingerprint string `json:"fingerprint"`
+		} `json:"utls"`
+		Reality struct {
+			Enabled   bool   `json:"enabled"`
+			PublicKey string `json:"public_key"`
+			ShortID   string `json:"short_id"`
+		} `json:"reality"`
+	} `json:"tls"`
+	Transport struct {
+		Type        string              `json:"type"`
+		Path        string              `json:"path"`
+		Host        listable            `json:"host"`
+		Headers     map[string]listable `json:"headers"`
+		ServiceName string              `json:"service_name"`
+	} `json:"transport"`
+	Obfs struct {
+		Type     string `json:"type"`
+		Password string `json:"password"`
+	} `json:"obfs"`
+}
+
+// singBoxIgnored are outbound types that are not proxy servers
+var singBoxIgnored = []string{"direct", "block", "dns", "selector", "urltest"}
+
+func parseSingBox(data []byte) ([]SubscriptionNode, []string, error) {
+	var config struct {
+		Outbounds []singBoxOutbound `json:"outbounds"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse sing-box config: %w", err)
+	}
+
+	var nodes []SubscriptionNode
+	var skipped []string
+	for _, o := range config.Outbounds {
+		if contains(singBoxIgnored, o.Type) {
+			continue
+		}
+		link, err := o.encoder()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", o.Tag, err))
+			continue
+		}
+		nodes = append(nodes, SubscriptionNode{Name: o.Tag, Link: link.Encode()})
+	}
+	if len(nodes) == 0 && len(skipped) == 0 {
+		return nil, nil, fmt.Errorf("sing-box config has no proxy outbounds")
+	}
+	return nodes, skipped, nil
+}
+
+func (o *singBoxOutbound) encoder() (Encoder, error) {
+	alpn := strings.Join(o.TLS.ALPN, ",")
+	switch o.Type {
+	case "vmess":
+		return &VMess{Name: o.Tag, Address: o.Server, Port: o.ServerPort, ID: o.UUID, AlterID: o.AlterID, Cipher: o.Security, Transport: o.transport()}, nil
+	case "vless":
+		return &VLESS{Name: o.Tag, Address: o.Server, Port: o.ServerPort, UUID: o.UUID, Flow: o.Flow, Transport: o.transport()}, nil
+	case "trojan":
+		return &Trojan{Name: o.Tag, Address: o.Server, Port: o.ServerPort, Password: o.Password, Transport: o.transport()}, nil
+	case "shadowsocks":
+		plugin := o.Plugin
+		if plugin != "" && o.PluginOpts != "" {
+			plugin += ";" + o.PluginOpts
+		}
+		return &Shadowsocks{Name: o.Tag, Address: o.Server, Port: o.ServerPort, Method: o.Method, Password: o.Password, Plugin: plugin}, nil
+	case "hysteria2":
+		return &Hysteria2{
+			Name: o.Tag, Address: o.Server, Port: o.ServerPort, Password: o.Password, SNI: o.TLS.ServerName,
+			Insecure: o.TLS.Insecure, Obfs: o.Obfs.Type, ObfsPassword: o.Obfs.Password,
+		}, nil
+	case "tuic":
+		return &TUIC{
+			Name: o.Tag, Address: o.Server, Port: o.ServerPort, UUID: o.UUID, Password: o.Password,
+			CongestionControl: o.Congestion, UDPRelayMode: o.UDPRelayMode,
+			ALPN: alpn, SNI: o.TLS.ServerName, AllowInsecure: o.TLS.Insecure,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported outbound type %q", o.Type)
+}
+
+func (o *singBoxOutbound) transport() ProxyTransport {
+	t := ProxyTransport{
+		Network:       "tcp",
+		SNI:           o.TLS.ServerName,
+		ALPN:          strings.Join(o.TLS.ALPN, ","),
+		AllowInsecure: o.TLS.Insecure,
+	}
+	if o.TLS.Enabled {
+		t.Security = "tls"
+	} else if o.Type == "trojan" {
+		t.Security = "none"
+	}
+	if o.TLS.UTLS.Enabled {
+		t.Fingerprint = o.TLS.UTLS.Fingerprint
+	}
+	if o.TLS.Reality.Enabled {
+		t.Security = "reality"
+		t.PublicKey = o.TLS.Reality.PublicKey
+		t.ShortID = o.TLS.Reality.ShortID
+	}
+
+	tr := o.Transport
+	switch tr.Type {
+	case "ws", "httpupgrade":
+		t.Network = tr.Type
+		t.Path = tr.Path
+		if host := tr.Headers["Host"]; len(host) > 0 {
+			t.Host = host[0]
+		} else if len(tr.Host) > 0 {
+			t.Host = tr.Host[0]
+		}
+	case "http":
+		// sing-box's http transport is HTTP/2 over TLS
+		t.Network = "h2"
+		t.Path = tr.Path
+		t.Host = strings.Join(tr.Host, ",")
+	case "grpc":
+		t.Network = "grpc"
+		t.ServiceName = tr.ServiceName
+	case "quic":
+		t.Network = "quic"
+	}
+	return t
+}
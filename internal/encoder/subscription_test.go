@@ -0,0 +1,224 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+const testClash = `
+port: 7890
+proxy-providers:
+  remote:
+    url: https://example.com/sub
+proxies:
+  - name: "🇭🇰 HK 01"
+    type: vmess
+    server: hk.example.com
+    port: 443
+    uuid: b831381d-6324-4d53-ad4f-8cda48b30811
+    alterId: 0
+    cipher: auto
+    tls: true
+    servername: hk.example.com
+    network: ws
+    ws-opts:
+      path: /ray
+      headers:
+        Host: cdn.example.com
+  - name: JP Reality
+    type: vless
+    server: 1.2.3.4
+    port: 443
+    uuid: b831381d-6324-4d53-ad4f-8cda48b30811
+    flow: xtls-rprx-vision
+    tls: true
+    servername: www.microsoft.com
+    client-fingerprint: chrome
+    reality-opts:
+      public-key: jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0
+      short-id: 0123abcd
+  - name: US Trojan
+    type: trojan
+    server: us.example.com
+    port: 443
+    password: secret
+    sni: us.example.com
+    network: grpc
+    grpc-opts:
+      grpc-service-name: svc
+  - name: SS obfs
+    type: ss
+    server: 5.6.7.8
+    port: 8388
+    cipher: aes-256-gcm
+    password: pw
+    plugin: obfs
+    plugin-opts:
+      mode: http
+      host: bing.com
+  - name: HY2
+    type: hysteria2
+    server: hy.example.com
+    port: 443
+    password: letmein
+    obfs: salamander
+    obfs-password: pw
+  - name: WireGuard
+    type: wireguard
+    server: wg.example.com
+    port: 51820
+proxy-groups:
+  - name: Auto
+    type: url-test
+    proxies: [HK 01]
+`
+
+const testSingBox = `{
+  "outbounds": [
+    {"type": "selector", "tag": "proxy", "outbounds": ["hk"]},
+    {
+      "type": "vless", "tag": "hk", "server": "hk.example.com", "server_port": 443,
+      "uuid": "b831381d-6324-4d53-ad4f-8cda48b30811", "flow": "xtls-rprx-vision",
+      "tls": {
+        "enabled": true, "server_name": "www.microsoft.com",
+        "utls": {"enabled": true, "fingerprint": "chrome"},
+        "reality": {"enabled": true, "public_key": "jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0", "short_id": "0123abcd"}
+      }
+    },
+    {
+      "type": "vmess", "tag": "ws", "server": "ws.example.com", "server_port": 443,
+      "uuid": "b831381d-6324-4d53-ad4f-8cda48b30811", "security": "auto",
+      "tls": {"enabled": true, "alpn": "h2"},
+      "transport": {"type": "ws", "path": "/ray", "headers": {"Host": "cdn.example.com"}}
+    },
+    {
+      "type": "shadowsocks", "tag": "ss", "server": "5.6.7.8", "server_port": 8388,
+      "method": "2022-blake3-aes-128-gcm", "password": "AAECAwQFBgcICQoLDA0ODw==",
+      "plugin": "v2ray-plugin", "plugin_opts": "tls;host=example.com"
+    },
+    {
+      "type": "tuic", "tag": "tuic", "server": "tuic.example.com", "server_port": 443,
+      "uuid": "b831381d-6324-4d53-ad4f-8cda48b30811", "password": "pw",
+      "congestion_control": "bbr", "tls": {"enabled": true, "alpn": ["h3"]}
+    },
+    {"type": "wireguard", "tag": "wg"},
+    {"type": "direct", "tag": "direct"}
+  ]
+}`
+
+func TestParseSubscription(t *testing.T) {
+	links := "trojan://secret@example.com:443?security=tls#Node%20A\n" +
+		"ss://YWVzLTEyOC1nY206dGVzdA@192.168.100.1:8888#Node%20B\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(links))
+
+	tests := []struct {
+		name     string
+		input    string
+		names    []string
+		contains []string // Substring of each link, in order
+		skipped  int
+	}{
+		{
+			name:  "clash",
+			input: testClash,
+			names: []string{"🇭🇰 HK 01", "JP Reality", "US Trojan", "SS obfs", "HY2"},
+			contains: []string{
+				"vmess://",
+				"pbk=jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0&security=reality",
+				"serviceName=svc",
+				"plugin=obfs-local%3Bobfs%3Dhttp%3Bobfs-host%3Dbing.com",
+				"obfs=salamander",
+			},
+			skipped: 1,
+		},
+		{
+			name:  "sing-box",
+			input: testSingBox,
+			names: []string{"hk", "ws", "ss", "tuic"},
+			contains: []string{
+				"fp=chrome",
+				"vmess://",
+				"plugin=v2ray-plugin%3Btls%3Bhost%3Dexample.com",
+				"alpn=h3",
+			},
+			skipped: 1,
+		},
+		{
+			name:  "clash with a URL comment",
+			input: "# Updated from https://sub.example.com/clash\n" + testClash,
+			names: []string{"🇭🇰 HK 01", "JP Reality", "US Trojan", "SS obfs", "HY2"},
+			contains: []string{
+				"vmess://",
+				"pbk=jNXHt1yRo0vDuchQlIP6Z0ZvjT3KtzVI-T4E7RoLJS0&security=reality",
+				"serviceName=svc",
+				"plugin=obfs-local%3Bobfs%3Dhttp%3Bobfs-host%3Dbing.com",
+				"obfs=salamander",
+			},
+			skipped: 1,
+		},
+		{
+			name:     "share links",
+			input:    links,
+			names:    []string{"Node A", "Node B"},
+			contains: []string{"trojan://", "ss://"},
+		},
+		{
+			name:     "base64 share links",
+			input:    encoded,
+			names:    []string{"Node A", "Node B"},
+			contains: []string{"trojan://", "ss://"},
+		},
+		{
+			name:     "wrapped base64",
+			input:    "\ufeff" + encoded[:40] + "\r\n" + encoded[40:],
+			names:    []string{"Node A", "Node B"},
+			contains: []string{"trojan://", "ss://"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, skipped, err := ParseSubscription([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseSubscription() unexpected error: %v", err)
+			}
+			if len(skipped) != tt.skipped {
+				t.Errorf("ParseSubscription() skipped %q, want %d", skipped, tt.skipped)
+			}
+			if len(nodes) != len(tt.names) {
+				t.Fatalf("ParseSubscription() returned %d nodes, want %d", len(nodes), len(tt.names))
+			}
+			for i, node := range nodes {
+				if node.Name != tt.names[i] {
+					t.Errorf("node %d name = %q, want %q", i, node.Name, tt.names[i])
+				}
+				if !strings.Contains(node.Link, tt.contains[i]) {
+					t.Errorf("node %d link = %q, want it to contain %q", i, node.Link, tt.contains[i])
+				}
+				if _, err := Detect(node.Link); err != nil {
+					t.Errorf("node %d link %q is invalid: %v", i, node.Link, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSubscriptionInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"just some text",
+		"https://example.com\n",
+		"proxies: []\n",
+		`{"outbounds": [{"type": "direct"}]}`,
+		`{"outbounds": [`,
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, _, err := ParseSubscription([]byte(input)); err == nil {
+				t.Errorf("ParseSubscription(%q) expected error, got nil", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^([A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?\.?$`)
+)
+
+var (
+	proxyNetworks   = []string{"tcp", "raw", "ws", "grpc", "h2", "http", "httpupgrade", "xhttp", "splithttp", "kcp", "mkcp", "quic"}
+	proxySecurities = []string{"", "none", "tls", "reality", "xtls"}
+)
+
+// Shadowsocks ciphers, with the key length in bytes of the 2022 ones
+var (
+	ssCiphers = []string{
+		"none", "plain", "table", "rc4-md5",
+		"aes-128-gcm", "aes-192-gcm", "aes-256-gcm",
+		"chacha20-ietf-poly1305", "xchacha20-ietf-poly1305",
+		"aes-128-cfb", "aes-192-cfb", "aes-256-cfb",
+		"aes-128-ctr", "aes-192-ctr", "aes-256-ctr",
+		"camellia-128-cfb", "camellia-192-cfb", "camellia-256-cfb",
+		"bf-cfb", "salsa20", "chacha20", "chacha20-ietf",
+	}
+	ss2022Ciphers = map[string]int{
+		"2022-blake3-aes-128-gcm":        16,
+		"2022-blake3-aes-256-gcm":        32,
+		"2022-blake3-chacha20-poly1305":  32,
+		"2022-blake3-chacha8-poly1305":   32,
+		"2022-blake3-xchacha20-poly1305": 32,
+	}
+)
+
+// parseProxy breaks a share link down into named fields, collecting every
+// problem found along the way. Links of schemes without a published
+// format are only checked for a non-empty body.
+func parseProxy(link string) ([]Field, []error) {
+	scheme, body, ok := strings.Cut(link, "://")
+	if !ok {
+		return nil, []error{fmt.Errorf("not a share link: missing ://")}
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, []error{fmt.Errorf("%s link is empty", scheme)}
+	}
+
+	p := &proxyParser{}
+	switch strings.ToLower(scheme) {
+	case "vmess":
+		p.vmess(body)
+	case "vless":
+		p.vless(link)
+	case "trojan":
+		p.trojan(link)
+	case "ss":
+		p.shadowsocks(body)
+	case "hysteria2", "hy2":
+		p.hysteria2(link)
+	case "tuic":
+		p.tuic(link)
+	}
+	return p.fields, p.problems
+}
+
+type proxyParser struct {
+	fields   []Field
+	problems []error
+}
+
+func (p *proxyParser) add(name, value string) {
+	if value != "" {
+		p.fields = append(p.fields, Field{Name: name, Value: value})
+	}
+}
+
+func (p *proxyParser) fail(format string, args ...any) {
+	p.problems = append(p.problems, fmt.Errorf(format, args...))
+}
+
+// server checks the host and port of a link
+func (p *proxyParser) server(host, port string) {
+	p.add("Server", host)
+	p.add("Port", port)
+
+	switch {
+	case host == "":
+		p.fail("missing server address")
+	case net.ParseIP(host) == nil && !hostnamePattern.MatchString(host):
+		p.fail("invalid server address %q", host)
+	}
+
+	if port == "" {
+		p.fail("missing port")
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		p.fail("invalid port %q, must be 1-65535", port)
+	}
+}
+
+func (p *proxyParser) uuid(name, id string) {
+	p.add(name, id)
+	switch {
+	case id == "":
+		p.fail("missing %s", strings.ToLower(name))
+	case !uuidPattern.MatchString(id):
+		p.fail("%s %q is not a UUID", strings.ToLower(name), id)
+	}
+}
+
+// transport checks the transport and TLS query parameters shared by
+// VLESS and Trojan
+func (p *proxyParser) transport(q url.Values) {
+	network := q.Get("type")
+	p.add("Network", network)
+	if network != "" && !contains(proxyNetworks, network) {
+		p.fail("unknown transport type %q", network)
+	}
+
+	security := q.Get("security")
+	p.add("Security", security)
+	if !contains(proxySecurities, security) {
+		p.fail("unknown security %q (use none, tls or reality)", security)
+	}
+	if security == "reality" && q.Get("pbk") == "" {
+		p.fail("reality security requires a public key (pbk)")
+	}
+
+	p.add("Host", q.Get("host"))
+	p.add("Path", q.Get("path"))
+	p.add("Service name", q.Get("serviceName"))
+	p.add("SNI", q.Get("sni"))
+	p.add("Fingerprint", q.Get("fp"))
+}
+
+// vmess parses the v2rayN base64 JSON format
+func (p *proxyParser) vmess(body string) {
+	// Some clients append a name or parameters to the JSON blob
+	body, _, _ = strings.Cut(body, "#")
+	data, err := decodeBase64(body)
+	if err != nil {
+		p.fail("vmess link is not valid base64 (truncated or mangled?)")
+		return
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		p.fail("vmess link does not contain valid JSON: %v", err)
+		return
+	}
+	str := func(key string) string {
+		switch v := config[key].(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return ""
+	}
+
+	p.add("Name", str("ps"))
+	p.server(str("add"), str("port"))
+	p.uuid("ID", str("id"))
+	if aid := str("aid"); aid != "" {
+		if n, err := strconv.Atoi(aid); err != nil || n < 0 {
+			p.fail("invalid alter ID %q", aid)
+		}
+	}
+	if v := str("v"); v != "" && v != "2" {
+		p.fail("unsupported vmess link version %q, want 2", v)
+	}
+
+	network := str("net")
+	p.add("Network", network)
+	if network != "" && !contains(proxyNetworks, network) {
+		p.fail("unknown transport type %q", network)
+	}
+	security := str("tls")
+	p.add("Security", security)
+	if !contains(proxySecurities, security) {
+		p.fail("unknown security %q (use none or tls)", security)
+	}
+	p.add("Host", str("host"))
+	p.add("Path", str("path"))
+	p.add("SNI", str("sni"))
+}
+
+func (p *proxyParser) vless(link string) {
+	u, ok := p.parseURL(link)
+	if !ok {
+		return
+	}
+	p.uuid("UUID", u.User.Username())
+	p.add("Flow", u.Query().Get("flow"))
+	p.transport(u.Query())
+}
+
+func (p *proxyParser) trojan(link string) {
+	u, ok := p.parseURL(link)
+	if !ok {
+		return
+	}
+	password := u.User.Username()
+	p.add("Password", password)
+	if password == "" {
+		p.fail("missing password")
+	}
+	p.transport(u.Query())
+}
+
+// parseURL parses a URI style share link and checks its server
+func (p *proxyParser) parseURL(link string) (*url.URL, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		p.fail("invalid link: %v", err)
+		return nil, false
+	}
+	p.add("Name", u.Fragment)
+	p.server(u.Hostname(), u.Port())
+	return u, true
+}
+
+// shadowsocks parses SIP002 links, with base64 or (for Shadowsocks 2022)
+// percent-encoded user info, and the legacy fully base64 encoded form
+func (p *proxyParser) shadowsocks(body string) {
+	body, name, _ := strings.Cut(body, "#")
+	if name, err := url.PathUnescape(name); err == nil {
+		p.add("Name", name)
+	}
+
+	userinfo, rest, ok := strings.Cut(body, "@")
+	if !ok {
+		// Legacy: ss://base64(method:password@host:port)
+		data, err := decodeBase64(body)
+		if err != nil || !strings.Contains(string(data), "@") {
+			p.fail("ss link has no server (expected method:password@host:port)")
+			return
+		}
+		at := strings.LastIndex(string(data), "@")
+		userinfo, rest = string(data[:at]), string(data[at+1:])
+	} else {
+		if decoded, err := url.PathUnescape(userinfo); err == nil {
+			userinfo = decoded
+		}
+		if !strings.Contains(userinfo, ":") {
+			data, err := decodeBase64(userinfo)
+			if err != nil {
+				p.fail("ss user info is neither base64 nor method:password")
+				return
+			}
+			userinfo = string(data)
+		}
+	}
+
+	method, password, ok := strings.Cut(userinfo, ":")
+	p.add("Method", method)
+	p.add("Password", password)
+	switch {
+	case !ok || method == "":
+		p.fail("ss user info %q is not method:password", userinfo)
+	case password == "" && method != "none" && method != "plain":
+		p.fail("missing password")
+	}
+	if keyLen, ok := ss2022Ciphers[method]; ok {
+		// Multi-user servers give "iPSK:uPSK"
+		for _, key := range strings.Split(password, ":") {
+			if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != keyLen {
+				p.fail("%s needs a base64 encoded %d byte key, got %q", method, keyLen, key)
+			}
+		}
+	} else if method != "" && !contains(ssCiphers, method) {
+		p.fail("unknown ss cipher %q", method)
+	}
+
+	hostport, query, _ := strings.Cut(rest, "?")
+	hostport = strings.TrimSuffix(hostport, "/")
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, ""
+	}
+	p.server(host, port)
+
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		p.fail("invalid ss query: %v", err)
+		return
+	}
+	if plugin := q.Get("plugin"); plugin != "" {
+		p.add("Plugin", plugin)
+		p.plugin(plugin)
+	}
+}
+
+// plugin checks a SIP003 plugin specification: a name followed by
+// ;-separated options, each key=value or a bare flag
+func (p *proxyParser) plugin(spec string) {
+	parts := strings.Split(spec, ";")
+	name := parts[0]
+	if name == "" {
+		p.fail("plugin %q has no name", spec)
+		return
+	}
+
+	options := make(map[string]string)
+	for _, opt := range parts[1:] {
+		key, value, hasValue := strings.Cut(opt, "=")
+		if key == "" {
+			p.fail("plugin %q has an empty option", spec)
+			continue
+		}
+		if hasValue && value == "" {
+			p.fail("plugin option %q has no value", key)
+		}
+		options[key] = value
+	}
+
+	switch name {
+	case "obfs-local", "simple-obfs":
+		if obfs := options["obfs"]; obfs != "http" && obfs != "tls" {
+			p.fail("%s needs obfs=http or obfs=tls, got %q", name, obfs)
+		}
+	case "v2ray-plugin", "xray-plugin":
+		if mode, ok := options["mode"]; ok && mode != "websocket" && mode != "quic" && mode != "grpc" {
+			p.fail("%s mode must be websocket, quic or grpc, got %q", name, mode)
+		}
+	}
+}
+
+func (p *proxyParser) hysteria2(link string) {
+	// The port may list ranges for port hopping, e.g. 443,5000-6000,
+	// which url.Parse rejects
+	link, ports := cutPortHopping(link)
+	u, ok := p.parseURL(link)
+	if !ok {
+		return
+	}
+	if ports != "" {
+		p.add("Port hopping", ports)
+		for _, r := range strings.Split(ports, ",") {
+			lo, hi, isRange := strings.Cut(r, "-")
+			a, errA := strconv.Atoi(lo)
+			b, errB := strconv.Atoi(hi)
+			if errA != nil || a < 1 || a > 65535 || (isRange && (errB != nil || b < a || b > 65535)) {
+				p.fail("invalid port range %q", r)
+			}
+		}
+	}
+	if u.User != nil {
+		password, ok := u.User.Password()
+		if ok {
+			password = u.User.Username() + ":" + password
+		} else {
+			password = u.User.Username()
+		}
+		p.add("Password", password)
+	}
+
+	q := u.Query()
+	p.add("SNI", q.Get("sni"))
+	if obfs := q.Get("obfs"); obfs != "" {
+		p.add("Obfuscation", obfs)
+		if obfs != "salamander" {
+			p.fail("unknown obfuscation %q (use salamander)", obfs)
+		} else if q.Get("obfs-password") == "" {
+			p.fail("salamander obfuscation requires obfs-password")
+		}
+	}
+}
+
+func (p *proxyParser) tuic(link string) {
+	u, ok := p.parseURL(link)
+	if !ok {
+		return
+	}
+
+	p.uuid("UUID", u.User.Username())
+	password, _ := u.User.Password()
+	p.add("Password", password)
+	if password == "" {
+		p.fail("missing password")
+	}
+
+	q := u.Query()
+	if cc := q.Get("congestion_control"); cc != "" && cc != "bbr" && cc != "cubic" && cc != "new_reno" {
+		p.fail("unknown congestion control %q (use bbr, cubic or new_reno)", cc)
+	}
+	if mode := q.Get("udp_relay_mode"); mode != "" && mode != "native" && mode != "quic" {
+		p.fail("unknown UDP relay mode %q (use native or quic)", mode)
+	}
+	p.add("SNI", q.Get("sni"))
+}
+
+// cutPortHopping replaces a multi-port list in the authority of link with
+// its first port, returning the full list separately
+func cutPortHopping(link string) (string, string) {
+	scheme, rest, _ := strings.Cut(link, "://")
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority := rest[:end]
+	colon := strings.LastIndex(authority, ":")
+	if colon < strings.LastIndex(authority, "@") || colon < strings.LastIndex(authority, "]") {
+		return link, ""
+	}
+	ports := authority[colon+1:]
+	if !strings.ContainsAny(ports, ",-") {
+		return link, ""
+	}
+	first := ports[:strings.IndexAny(ports, ",-")]
+	return scheme + "://" + authority[:colon+1] + first + rest[end:], ports
+}
+
+// decodeBase64 accepts standard and URL-safe base64, with or without
+// padding
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	encodings := []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	}
+	var err error
+	for _, enc := range encodings {
+		var data []byte
+		if data, err = enc.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -103,3 +103,169 @@ func escapeVCard(s string) string {
 	)
 	return replacer.Replace(s)
 }
+
+// ParseVCard parses a vCard (versions 2.1 to 4.0) back into a VCard.
+// Folded lines are joined, values are unescaped and properties the VCard
+// struct has no field for are ignored.
+func ParseVCard(s string) (*VCard, error) {
+	lines := unfoldLines(s)
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCARD") {
+		return nil, fmt.Errorf("not a vCard payload: missing BEGIN:VCARD")
+	}
+
+	v := &VCard{}
+	ended := false
+	var formattedName string
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, types, value, ok := parseContentLine(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid vCard line: %s", line)
+		}
+
+		switch name {
+		case "END":
+			ended = true
+		case "N":
+			parts := splitEscaped(value, ';')
+			v.LastName = unescapeVCard(parts[0])
+			if len(parts) > 1 {
+				v.FirstName = unescapeVCard(parts[1])
+			}
+		case "FN":
+			formattedName = unescapeVCard(value)
+		case "ORG":
+			v.Organization = joinComponents(value, ", ")
+		case "TITLE":
+			v.Title = unescapeVCard(value)
+		case "TEL":
+			switch {
+			case types["work"]:
+				setFirst(&v.PhoneWork, unescapeVCard(value))
+			case types["cell"] || types["mobile"]:
+				setFirst(&v.PhoneMobile, unescapeVCard(value))
+			default:
+				setFirst(&v.Phone, unescapeVCard(value))
+			}
+		case "EMAIL":
+			if types["work"] {
+				setFirst(&v.EmailWork, unescapeVCard(value))
+			} else {
+				setFirst(&v.Email, unescapeVCard(value))
+			}
+		case "URL":
+			setFirst(&v.Website, unescapeVCard(value))
+		case "ADR":
+			v.Address = joinComponents(value, ", ")
+		case "NOTE":
+			v.Note = unescapeVCard(value)
+		}
+		if ended {
+			break
+		}
+	}
+
+	if !ended {
+		return nil, fmt.Errorf("vCard payload has no END:VCARD")
+	}
+	if v.FirstName == "" && v.LastName == "" {
+		v.FirstName = formattedName
+	}
+	return v, nil
+}
+
+// unfoldLines splits s into lines, joining continuation lines (starting
+// with a space or tab) onto the line before
+func unfoldLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseContentLine splits "NAME;TYPE=a,b:value" into the upper-case
+// property name, the set of lower-case types and the raw value. Group
+// prefixes such as "item1." are dropped.
+func parseContentLine(line string) (name string, types map[string]bool, value string, ok bool) {
+	// The value starts at the first colon outside a quoted parameter
+	quoted := false
+	colon := -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	params := strings.Split(line[:colon], ";")
+	name = strings.ToUpper(params[0])
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	types = make(map[string]bool)
+	for _, p := range params[1:] {
+		key, val, hasValue := strings.Cut(p, "=")
+		if !hasValue {
+			// vCard 2.1 lists bare types, e.g. TEL;WORK;VOICE
+			types[strings.ToLower(key)] = true
+			continue
+		}
+		if !strings.EqualFold(key, "TYPE") {
+			continue
+		}
+		for _, t := range strings.Split(strings.Trim(val, `"`), ",") {
+			types[strings.ToLower(t)] = true
+		}
+	}
+	return name, types, line[colon+1:], true
+}
+
+// joinComponents unescapes the ;-separated components of a structured
+// value and joins the non-empty ones with sep
+func joinComponents(value, sep string) string {
+	var parts []string
+	for _, p := range splitEscaped(value, ';') {
+		if p = strings.TrimSpace(unescapeVCard(p)); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// setFirst stores value in field unless an earlier property filled it
+func setFirst(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// unescapeVCard reverses escapeVCard
+func unescapeVCard(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
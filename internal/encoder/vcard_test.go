@@ -157,3 +157,99 @@ func TestEscapeVCard(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVCard(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected VCard
+		hasError bool
+	}{
+		{
+			name: "vCard 3.0 with types",
+			input: "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Doe;John;;;\r\nFN:John Doe\r\nORG:Acme\\, Inc.;Sales\r\n" +
+				"TEL;TYPE=work,voice:+1 555 0100\r\nTEL;TYPE=CELL:+1 555 0101\r\nTEL:+1 555 0102\r\n" +
+				"EMAIL;TYPE=INTERNET,WORK:john@acme.example\r\nitem1.URL:https://acme.example\r\n" +
+				"ADR;TYPE=work:;;1 Main St;Springfield;;12345;USA\r\nNOTE:Line one\\nLine two\r\nEND:VCARD",
+			expected: VCard{
+				FirstName: "John", LastName: "Doe", Organization: "Acme, Inc., Sales",
+				PhoneWork: "+1 555 0100", PhoneMobile: "+1 555 0101", Phone: "+1 555 0102",
+				EmailWork: "john@acme.example", Website: "https://acme.example",
+				Address: "1 Main St, Springfield, 12345, USA", Note: "Line one\nLine two",
+			},
+		},
+		{
+			name:     "vCard 2.1 bare types and folding",
+			input:    "BEGIN:VCARD\nVERSION:2.1\nN:Smith;Anna\nTEL;WORK;VOICE:123\nNOTE:folded\n  across lines\nEND:VCARD",
+			expected: VCard{FirstName: "Anna", LastName: "Smith", PhoneWork: "123", Note: "folded across lines"},
+		},
+		{
+			name:     "FN only",
+			input:    "BEGIN:VCARD\nVERSION:4.0\nFN:Team Inbox\nEMAIL:team@example.com\nEND:VCARD",
+			expected: VCard{FirstName: "Team Inbox", Email: "team@example.com"},
+		},
+		{name: "missing begin", input: "VERSION:3.0\nFN:X\nEND:VCARD", hasError: true},
+		{name: "missing end", input: "BEGIN:VCARD\nFN:X", hasError: true},
+		{name: "line without colon", input: "BEGIN:VCARD\nFN X\nEND:VCARD", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseVCard(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseVCard() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVCard() unexpected error: %v", err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseVCard() = %+v, want %+v", *result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseVCardRoundTrip(t *testing.T) {
+	original := VCard{
+		FirstName: "Zoë", LastName: "O'Brien; Jr.", Organization: "Foo, Bar & Co", Title: "CTO",
+		Phone: "+44 20 7946 0000", PhoneWork: "+44 20 7946 0001", PhoneMobile: "+44 7700 900000",
+		Email: "zoe@example.com", EmailWork: "zoe@work.example", Website: "https://example.com",
+		Address: "1 High St, London", Note: "Back\\slash\nand newline",
+	}
+	parsed, err := ParseVCard(original.Encode())
+	if err != nil {
+		t.Fatalf("ParseVCard() unexpected error: %v", err)
+	}
+	if *parsed != original {
+		t.Errorf("ParseVCard(Encode()) = %+v, want %+v", *parsed, original)
+	}
+}
+
+func TestParseVCardEscapedContacts(t *testing.T) {
+	// Exporters escape commas and semicolons in every text value
+	input := "BEGIN:VCARD\nVERSION:3.0\nN:Doe;Jane\nTEL;TYPE=CELL:+1 555 0100\\,\\,42\nEMAIL:\"Doe\\, Jane\"@example.com\nURL:https://example.com/?a=1\\;b=2\nEND:VCARD"
+	want := VCard{
+		FirstName: "Jane", LastName: "Doe", PhoneMobile: "+1 555 0100,,42",
+		Email: `"Doe, Jane"@example.com`, Website: "https://example.com/?a=1;b=2",
+	}
+
+	parsed, err := ParseVCard(input)
+	if err != nil {
+		t.Fatalf("ParseVCard() unexpected error: %v", err)
+	}
+	if *parsed != want {
+		t.Errorf("ParseVCard() = %+v, want %+v", *parsed, want)
+	}
+
+	// Writing the contact back and reading it again must not grow escapes
+	again, err := ParseVCard(parsed.Encode())
+	if err != nil {
+		t.Fatalf("ParseVCard(Encode()) unexpected error: %v", err)
+	}
+	if *again != want {
+		t.Errorf("ParseVCard(Encode()) = %+v, want %+v", *again, want)
+	}
+}
@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -9,35 +10,75 @@ import (
 type WiFiEncryption string
 
 const (
-	WPA    WiFiEncryption = "WPA"
-	WEP    WiFiEncryption = "WEP"
-	NoPass WiFiEncryption = "nopass"
+	WPA     WiFiEncryption = "WPA"      // WPA/WPA2 Personal, or WPA2/WPA3 transition mode
+	SAE     WiFiEncryption = "SAE"      // WPA3 Personal only
+	WPA2EAP WiFiEncryption = "WPA2-EAP" // WPA2/WPA3 Enterprise (802.1X)
+	WEP     WiFiEncryption = "WEP"
+	NoPass  WiFiEncryption = "nopass"
 )
 
 // ParseWiFiEncryption parses encryption type from string
 func ParseWiFiEncryption(s string) (WiFiEncryption, error) {
 	switch strings.ToUpper(s) {
-	case "WPA", "WPA2", "WPA3", "WPA2/WPA3":
+	case "WPA", "WPA2", "WPA2/WPA3":
 		return WPA, nil
+	case "SAE", "WPA3":
+		return SAE, nil
+	case "WPA2-EAP", "WPA-EAP", "WPA3-EAP", "EAP", "802.1X":
+		return WPA2EAP, nil
 	case "WEP":
 		return WEP, nil
 	case "NOPASS", "NONE", "OPEN", "":
 		return NoPass, nil
 	default:
-		return "", fmt.Errorf("unknown encryption type: %s (use WPA, WEP, or nopass)", s)
+		return "", fmt.Errorf("unknown encryption type: %s (use WPA, SAE, WPA2-EAP, WEP, or nopass)", s)
 	}
 }
 
+// EAP methods and phase 2 methods understood by Android's WiFi QR parser
+var (
+	eapMethods    = []string{"PEAP", "TTLS", "TLS", "PWD", "SIM", "AKA", "AKA'"}
+	phase2Methods = []string{"PAP", "MSCHAP", "MSCHAPV2", "GTC", "SIM", "AKA", "AKA'"}
+)
+
+// ValidateEAP checks an 802.1X method and phase 2 method. The phase 2
+// method may be empty.
+func ValidateEAP(method, phase2 string) error {
+	if !contains(eapMethods, method) {
+		return fmt.Errorf("unknown EAP method: %s (use %s)", method, strings.Join(eapMethods, ", "))
+	}
+	if phase2 == "" {
+		return nil
+	}
+	if method != "PEAP" && method != "TTLS" {
+		return fmt.Errorf("EAP method %s has no phase 2 authentication", method)
+	}
+	if !contains(phase2Methods, phase2) {
+		return fmt.Errorf("unknown phase 2 method: %s (use %s)", phase2, strings.Join(phase2Methods, ", "))
+	}
+	return nil
+}
+
 // WiFi encodes WiFi network information
 type WiFi struct {
 	SSID       string
 	Password   string
 	Encryption WiFiEncryption
 	Hidden     bool
+	// TransitionDisable (R:1) tells WPA3 capable devices never to fall
+	// back to WPA2 on this network once they have joined it
+	TransitionDisable bool
+
+	// 802.1X settings for WPA2-EAP
+	EAPMethod         string // PEAP, TTLS, TLS, PWD, SIM, AKA, AKA'
+	Phase2            string // Inner authentication for PEAP/TTLS, e.g. MSCHAPV2
+	AnonymousIdentity string // Outer identity sent in the clear
+	Identity          string
 }
 
-// Encode returns the WiFi QR code format string
-// Format: WIFI:T:<encryption>;S:<ssid>;P:<password>;H:<hidden>;;
+// Encode returns the WiFi QR code format string, in the ZXing/Android
+// grammar with the WPA3 transition disable flag
+// Format: WIFI:T:<encryption>;R:1;S:<ssid>;P:<password>;E:<eap>;PH2:<phase2>;A:<anonymous>;I:<identity>;H:<hidden>;;
 func (w *WiFi) Encode() string {
 	// Escape special characters in SSID and password
 	ssid := escapeWiFiString(w.SSID)
@@ -45,19 +86,41 @@ func (w *WiFi) Encode() string {
 
 	encryption := w.Encryption
 	if encryption == "" {
-		if w.Password == "" {
+		switch {
+		case w.EAPMethod != "":
+			encryption = WPA2EAP
+		case w.Password == "":
 			encryption = NoPass
-		} else {
+		default:
 			encryption = WPA
 		}
 	}
 
+	transition := ""
+	if w.TransitionDisable {
+		transition = "R:1;"
+	}
+
+	var eap strings.Builder
+	if encryption == WPA2EAP {
+		for _, f := range []struct{ key, value string }{
+			{"E", w.EAPMethod},
+			{"PH2", w.Phase2},
+			{"A", w.AnonymousIdentity},
+			{"I", w.Identity},
+		} {
+			if f.value != "" {
+				eap.WriteString(f.key + ":" + escapeWiFiString(f.value) + ";")
+			}
+		}
+	}
+
 	hidden := ""
 	if w.Hidden {
 		hidden = "H:true;"
 	}
 
-	return fmt.Sprintf("WIFI:T:%s;S:%s;P:%s;%s;", encryption, ssid, password, hidden)
+	return fmt.Sprintf("WIFI:T:%s;%sS:%s;P:%s;%s%s;", encryption, transition, ssid, password, eap.String(), hidden)
 }
 
 // escapeWiFiString escapes special characters for WiFi QR format
@@ -72,3 +135,96 @@ func escapeWiFiString(s string) string {
 	)
 	return replacer.Replace(s)
 }
+
+// ParseWiFi parses a WIFI: payload back into a WiFi network. Fields may
+// appear in any order and unknown fields are ignored.
+func ParseWiFi(s string) (*WiFi, error) {
+	if !strings.HasPrefix(strings.ToUpper(s), "WIFI:") {
+		return nil, fmt.Errorf("not a WiFi payload: missing WIFI: prefix")
+	}
+
+	w := &WiFi{}
+	hasSSID := false
+	for _, field := range splitEscaped(s[len("WIFI:"):], ';') {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid WiFi field: %s", field)
+		}
+		value = unescapeWiFiString(value)
+
+		switch strings.ToUpper(key) {
+		case "T":
+			encryption, err := ParseWiFiEncryption(value)
+			if err != nil {
+				return nil, err
+			}
+			w.Encryption = encryption
+		case "S":
+			w.SSID = value
+			hasSSID = true
+		case "P":
+			w.Password = value
+		case "H":
+			w.Hidden = strings.EqualFold(value, "true")
+		case "R":
+			// A hex bitmap; bit 0 disables WPA3 Personal transition mode
+			if bits, err := strconv.ParseUint(value, 16, 8); err == nil {
+				w.TransitionDisable = bits&1 != 0
+			}
+		case "E":
+			w.EAPMethod = value
+		case "PH2":
+			w.Phase2 = value
+		case "A":
+			w.AnonymousIdentity = value
+		case "I":
+			w.Identity = value
+		}
+	}
+
+	if !hasSSID || w.SSID == "" {
+		return nil, fmt.Errorf("WiFi payload has no SSID")
+	}
+	if w.Encryption == "" {
+		w.Encryption = NoPass
+	}
+	return w, nil
+}
+
+// splitEscaped splits s at every sep not preceded by a backslash, keeping
+// escapes in the parts
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescapeWiFiString reverses escapeWiFiString. Values wrapped in
+// unescaped double quotes, as some generators write SSIDs that look like
+// hex, are unquoted.
+func unescapeWiFiString(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' && !strings.HasSuffix(s, `\"`) {
+		s = s[1 : len(s)-1]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
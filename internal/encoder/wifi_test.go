@@ -1,7 +1,6 @@
 package encoder
 
 import (
-	"strings"
 	"testing"
 )
 
@@ -62,6 +61,49 @@ func TestWiFiEncode(t *testing.T) {
 			},
 			expected: "WIFI:T:nopass;S:OpenNet;P:;;",
 		},
+		{
+			name: "WPA3 only with transition disable",
+			wifi: WiFi{
+				SSID:              "Office",
+				Password:          "password123",
+				Encryption:        SAE,
+				TransitionDisable: true,
+			},
+			expected: "WIFI:T:SAE;R:1;S:Office;P:password123;;",
+		},
+		{
+			name: "WPA2-EAP PEAP",
+			wifi: WiFi{
+				SSID:              "Corp",
+				Password:          "secret",
+				Encryption:        WPA2EAP,
+				EAPMethod:         "PEAP",
+				Phase2:            "MSCHAPV2",
+				AnonymousIdentity: "anonymous",
+				Identity:          `CORP\alice`,
+			},
+			expected: `WIFI:T:WPA2-EAP;S:Corp;P:secret;E:PEAP;PH2:MSCHAPV2;A:anonymous;I:CORP\\alice;;`,
+		},
+		{
+			name: "auto-detect WPA2-EAP from EAP method",
+			wifi: WiFi{
+				SSID:      "Corp",
+				Password:  "secret",
+				EAPMethod: "TTLS",
+				Identity:  "bob",
+			},
+			expected: "WIFI:T:WPA2-EAP;S:Corp;P:secret;E:TTLS;I:bob;;",
+		},
+		{
+			name: "EAP fields dropped without WPA2-EAP",
+			wifi: WiFi{
+				SSID:       "Home",
+				Password:   "password123",
+				Encryption: WPA,
+				Identity:   "bob",
+			},
+			expected: "WIFI:T:WPA;S:Home;P:password123;;",
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,7 +125,11 @@ func TestParseWiFiEncryption(t *testing.T) {
 		{"WPA", WPA, false},
 		{"wpa", WPA, false},
 		{"WPA2", WPA, false},
-		{"WPA3", WPA, false},
+		{"WPA3", SAE, false},
+		{"SAE", SAE, false},
+		{"WPA2/WPA3", WPA, false},
+		{"WPA2-EAP", WPA2EAP, false},
+		{"eap", WPA2EAP, false},
 		{"WEP", WEP, false},
 		{"wep", WEP, false},
 		{"nopass", NoPass, false},
@@ -136,3 +182,86 @@ func TestEscapeWiFiString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWiFi(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected WiFi
+		hasError bool
+	}{
+		{"WIFI:T:WPA;S:MyNetwork;P:password123;;", WiFi{SSID: "MyNetwork", Password: "password123", Encryption: WPA}, false},
+		{"WIFI:S:HiddenNet;T:WEP;P:secret;H:true;;", WiFi{SSID: "HiddenNet", Password: "secret", Encryption: WEP, Hidden: true}, false},
+		{`WIFI:T:WPA;S:My\;Network\:Test;P:pass\;word\\;;`, WiFi{SSID: "My;Network:Test", Password: `pass;word\`, Encryption: WPA}, false},
+		{`wifi:T:nopass;S:"012345";;`, WiFi{SSID: "012345", Encryption: NoPass}, false},
+		{"WIFI:S:Open;;", WiFi{SSID: "Open", Encryption: NoPass}, false},
+		{"WIFI:T:SAE;R:1;S:Office;P:password123;;", WiFi{SSID: "Office", Password: "password123", Encryption: SAE, TransitionDisable: true}, false},
+		{"WIFI:T:WPA;R:0;S:Home;P:password123;;", WiFi{SSID: "Home", Password: "password123", Encryption: WPA}, false},
+		{"WIFI:T:WPA2-EAP;S:Corp;P:secret;E:PEAP;PH2:MSCHAPV2;A:anon;I:alice;;", WiFi{SSID: "Corp", Password: "secret", Encryption: WPA2EAP, EAPMethod: "PEAP", Phase2: "MSCHAPV2", AnonymousIdentity: "anon", Identity: "alice"}, false},
+		{"WIFI:T:WPA;P:nossid;;", WiFi{}, true},
+		{"WIFI:T:XYZ;S:Net;;", WiFi{}, true},
+		{"S:Net;;", WiFi{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseWiFi(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseWiFi(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWiFi(%q) unexpected error: %v", tt.input, err)
+			}
+			if *result != tt.expected {
+				t.Errorf("ParseWiFi(%q) = %+v, want %+v", tt.input, *result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseWiFiRoundTrip(t *testing.T) {
+	original := WiFi{SSID: `Café "Net", 5G; \ :)`, Password: `p@ss;w:rd,"\`, Encryption: WPA, Hidden: true}
+	parsed, err := ParseWiFi(original.Encode())
+	if err != nil {
+		t.Fatalf("ParseWiFi() unexpected error: %v", err)
+	}
+	if *parsed != original {
+		t.Errorf("ParseWiFi(Encode()) = %+v, want %+v", *parsed, original)
+	}
+
+	enterprise := WiFi{SSID: "Corp", Password: `p;w`, Encryption: WPA2EAP, EAPMethod: "TTLS", Phase2: "PAP", AnonymousIdentity: "anon@corp", Identity: `CORP\bob`, TransitionDisable: true}
+	parsed, err = ParseWiFi(enterprise.Encode())
+	if err != nil {
+		t.Fatalf("ParseWiFi() unexpected error: %v", err)
+	}
+	if *parsed != enterprise {
+		t.Errorf("ParseWiFi(Encode()) = %+v, want %+v", *parsed, enterprise)
+	}
+}
+
+func TestValidateEAP(t *testing.T) {
+	tests := []struct {
+		method   string
+		phase2   string
+		hasError bool
+	}{
+		{"PEAP", "MSCHAPV2", false},
+		{"TTLS", "PAP", false},
+		{"TLS", "", false},
+		{"AKA'", "", false},
+		{"LEAP", "", true},
+		{"TLS", "MSCHAPV2", true},
+		{"PEAP", "CHAP", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.phase2, func(t *testing.T) {
+			err := ValidateEAP(tt.method, tt.phase2)
+			if (err != nil) != tt.hasError {
+				t.Errorf("ValidateEAP(%q, %q) error = %v, want error %v", tt.method, tt.phase2, err, tt.hasError)
+			}
+		})
+	}
+}
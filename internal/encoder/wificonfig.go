@@ -0,0 +1,324 @@
+package encoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseWiFiConfig reads the networks of a NetworkManager keyfile
+// (.nmconnection) or a wpa_supplicant.conf. Passwords that are not stored
+// in the file, such as NetworkManager secrets kept in a keyring, are left
+// empty.
+func ParseWiFiConfig(data []byte) ([]*WiFi, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	if isWPASupplicant(data) {
+		return parseWPASupplicant(data)
+	}
+	return parseNMConnection(data)
+}
+
+// isWPASupplicant reports whether data has a network={ block
+func isWPASupplicant(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(key) == "network" && strings.TrimSpace(value) == "{" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNMConnection reads a NetworkManager keyfile, an INI file with
+// [connection], [wifi], [wifi-security] and [802-1x] sections
+func parseNMConnection(data []byte) ([]*WiFi, error) {
+	sections := make(map[string]map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			// Older keyfiles use the full setting names
+			switch section {
+			case "802-11-wireless":
+				section = "wifi"
+			case "802-11-wireless-security":
+				section = "wifi-security"
+			}
+			if sections[section] == nil {
+				sections[section] = make(map[string]string)
+			}
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("not a NetworkManager keyfile or wpa_supplicant.conf")
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid keyfile line: %s", line)
+		}
+		sections[section][strings.TrimSpace(key)] = unescapeKeyfile(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keyfile: %w", err)
+	}
+
+	wifi, ok := sections["wifi"]
+	if !ok {
+		if _, ok := sections["connection"]; !ok {
+			return nil, fmt.Errorf("not a NetworkManager keyfile or wpa_supplicant.conf")
+		}
+		return nil, fmt.Errorf("NetworkManager connection %q is not a WiFi connection", sections["connection"]["id"])
+	}
+
+	w := &WiFi{
+		SSID:       nmSSID(wifi["ssid"]),
+		Hidden:     wifi["hidden"] == "true",
+		Encryption: NoPass,
+	}
+	if w.SSID == "" {
+		return nil, fmt.Errorf("NetworkManager connection has no SSID")
+	}
+
+	security := sections["wifi-security"]
+	switch strings.ToLower(security["key-mgmt"]) {
+	case "":
+	case "wpa-psk":
+		w.Encryption = WPA
+		w.Password = security["psk"]
+	case "sae":
+		w.Encryption = SAE
+		w.Password = security["psk"]
+	case "none":
+		// Static WEP
+		w.Encryption = WEP
+		w.Password = security["wep-key"+security["wep-tx-keyidx"]]
+		if security["wep-tx-keyidx"] == "" {
+			w.Password = security["wep-key0"]
+		}
+	case "wpa-eap", "ieee8021x", "wpa-eap-suite-b-192":
+		eap := sections["802-1x"]
+		w.Encryption = WPA2EAP
+		w.EAPMethod = strings.ToUpper(strings.Split(strings.Trim(eap["eap"], ";"), ";")[0])
+		w.Phase2 = strings.ToUpper(eap["phase2-auth"])
+		if w.Phase2 == "" {
+			w.Phase2 = strings.ToUpper(eap["phase2-autheap"])
+		}
+		w.AnonymousIdentity = eap["anonymous-identity"]
+		w.Identity = eap["identity"]
+		w.Password = eap["password"]
+	case "owe":
+		// Enhanced Open has no credentials to share
+	default:
+		return nil, fmt.Errorf("unsupported key management: %s", security["key-mgmt"])
+	}
+	return []*WiFi{w}, nil
+}
+
+// nmSSID decodes a keyfile SSID, which is either text or, in files
+// written by older NetworkManager versions, a list of byte values such as
+// 79;102;102;
+func nmSSID(value string) string {
+	if !strings.Contains(value, ";") {
+		return value
+	}
+	var b []byte
+	for _, part := range strings.Split(strings.TrimSuffix(value, ";"), ";") {
+		n, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return value
+		}
+		b = append(b, byte(n))
+	}
+	return string(b)
+}
+
+// unescapeKeyfile reverses GKeyFile value escapes
+func unescapeKeyfile(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseWPASupplicant reads every network={...} block of a
+// wpa_supplicant.conf
+func parseWPASupplicant(data []byte) ([]*WiFi, error) {
+	var networks []*WiFi
+	var block map[string]string
+	lineNum := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if block == nil {
+			key, value, _ := strings.Cut(line, "=")
+			if strings.TrimSpace(key) == "network" && strings.TrimSpace(value) == "{" {
+				block = make(map[string]string)
+			}
+			// Global settings (ctrl_interface, country, ...) are skipped
+			continue
+		}
+		if line == "}" {
+			w, err := wpaNetwork(block)
+			if err != nil {
+				return nil, fmt.Errorf("network ending on line %d: %w", lineNum, err)
+			}
+			networks = append(networks, w)
+			block = nil
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid wpa_supplicant line %d: %s", lineNum, line)
+		}
+		block[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wpa_supplicant.conf: %w", err)
+	}
+	if block != nil {
+		return nil, fmt.Errorf("unterminated network block")
+	}
+	return networks, nil
+}
+
+// wpaNetwork converts the settings of one network block
+func wpaNetwork(block map[string]string) (*WiFi, error) {
+	ssid, err := wpaString(block["ssid"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssid: %w", err)
+	}
+	if ssid == "" {
+		return nil, fmt.Errorf("network has no ssid")
+	}
+	w := &WiFi{SSID: ssid, Hidden: block["scan_ssid"] == "1"}
+
+	// wpa_supplicant defaults to "WPA-PSK WPA-EAP"
+	keyMgmt := strings.Fields(strings.ToUpper(block["key_mgmt"]))
+	if len(keyMgmt) == 0 {
+		keyMgmt = []string{"WPA-PSK", "WPA-EAP"}
+		if block["eap"] == "" && block["identity"] == "" {
+			keyMgmt = keyMgmt[:1]
+		}
+	}
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if contains(keyMgmt, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("WPA-EAP", "WPA-EAP-SHA256", "IEEE8021X", "WPA-EAP-SUITE-B-192") && block["eap"] != "":
+		w.Encryption = WPA2EAP
+		w.EAPMethod = strings.Fields(strings.ToUpper(block["eap"]))[0]
+		phase2, _ := wpaString(block["phase2"])
+		for _, part := range strings.Fields(phase2) {
+			// auth=MSCHAPV2 or autheap=GTC
+			if _, method, ok := strings.Cut(part, "="); ok {
+				w.Phase2 = strings.ToUpper(method)
+				break
+			}
+		}
+		w.AnonymousIdentity, _ = wpaString(block["anonymous_identity"])
+		w.Identity, _ = wpaString(block["identity"])
+		if strings.HasPrefix(block["password"], "hash:") {
+			return nil, fmt.Errorf("EAP password of %q is stored as an NT hash and cannot be shared", ssid)
+		}
+		w.Password, _ = wpaString(block["password"])
+	case has("WPA-PSK", "WPA-PSK-SHA256", "FT-PSK"):
+		// Transition mode when SAE is listed too
+		w.Encryption = WPA
+		w.Password, err = wpaPSK(block)
+	case has("SAE", "FT-SAE"):
+		w.Encryption = SAE
+		w.Password, err = wpaPSK(block)
+	case has("NONE") && block["wep_key0"] != "":
+		w.Encryption = WEP
+		index := block["wep_tx_keyidx"]
+		if index == "" {
+			index = "0"
+		}
+		w.Password, err = wpaString(block["wep_key"+index])
+	case has("NONE", "OWE"):
+		w.Encryption = NoPass
+	default:
+		return nil, fmt.Errorf("unsupported key_mgmt for %q: %s", ssid, block["key_mgmt"])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid password for %q: %w", ssid, err)
+	}
+	return w, nil
+}
+
+// wpaPSK returns the passphrase of a PSK or SAE network: sae_password
+// when set, otherwise psk, which may be a raw 64 hex digit key
+func wpaPSK(block map[string]string) (string, error) {
+	if value, ok := block["sae_password"]; ok {
+		return wpaString(value)
+	}
+	psk := block["psk"]
+	if psk != "" && !strings.HasPrefix(psk, `"`) {
+		// An unquoted psk is the raw 256-bit key, which WIFI: codes accept
+		return psk, nil
+	}
+	return wpaString(psk)
+}
+
+// wpaString decodes a wpa_supplicant string value: "quoted text",
+// P"printf escaped text", or unquoted hex bytes
+func wpaString(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", nil
+	case strings.HasPrefix(value, `P"`) && strings.HasSuffix(value, `"`) && len(value) >= 3:
+		unquoted, err := strconv.Unquote(value[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid escaped string %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) && len(value) >= 2:
+		return value[1 : len(value)-1], nil
+	default:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return "", fmt.Errorf("unquoted value is not hex: %s", value)
+		}
+		return string(b), nil
+	}
+}
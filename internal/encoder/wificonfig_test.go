@@ -0,0 +1,203 @@
+package encoder
+
+import (
+	"testing"
+)
+
+func TestParseWiFiConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []WiFi
+		hasError bool
+	}{
+		{
+			name: "NetworkManager WPA",
+			input: `[connection]
+id=Home
+uuid=0b5e8a3c-59c1-4f0e-9a36-6f1d0a6c3b9e
+type=wifi
+
+[wifi]
+mode=infrastructure
+ssid=Home\sNet
+hidden=true
+
+[wifi-security]
+key-mgmt=wpa-psk
+psk=pass;word 123
+`,
+			expected: []WiFi{{SSID: "Home Net", Password: "pass;word 123", Encryption: WPA, Hidden: true}},
+		},
+		{
+			name: "NetworkManager legacy names and byte SSID",
+			input: `[connection]
+id=Old
+type=802-11-wireless
+
+[802-11-wireless]
+ssid=79;108;100;
+
+[802-11-wireless-security]
+key-mgmt=sae
+psk=password123
+`,
+			expected: []WiFi{{SSID: "Old", Password: "password123", Encryption: SAE}},
+		},
+		{
+			name: "NetworkManager PEAP",
+			input: `[connection]
+id=Office
+type=wifi
+
+[wifi]
+ssid=Office
+
+[wifi-security]
+key-mgmt=wpa-eap
+
+[802-1x]
+eap=peap;
+identity=alice
+anonymous-identity=anonymous
+phase2-auth=mschapv2
+password=secret
+`,
+			expected: []WiFi{{
+				SSID: "Office", Password: "secret", Encryption: WPA2EAP,
+				EAPMethod: "PEAP", Phase2: "MSCHAPV2", AnonymousIdentity: "anonymous", Identity: "alice",
+			}},
+		},
+		{
+			name: "NetworkManager open and keyring secret",
+			input: `[connection]
+id=Cafe
+type=wifi
+[wifi]
+ssid=Cafe
+`,
+			expected: []WiFi{{SSID: "Cafe", Encryption: NoPass}},
+		},
+		{
+			name: "NetworkManager agent-owned secret",
+			input: `[connection]
+id=K
+type=wifi
+[wifi]
+ssid=K
+[wifi-security]
+key-mgmt=wpa-psk
+psk-flags=1
+`,
+			expected: []WiFi{{SSID: "K", Encryption: WPA}},
+		},
+		{
+			name: "NetworkManager WEP",
+			input: `[wifi]
+ssid=Old
+[wifi-security]
+key-mgmt=none
+wep-key1=abcde
+wep-tx-keyidx=1
+`,
+			expected: []WiFi{{SSID: "Old", Password: "abcde", Encryption: WEP}},
+		},
+		{
+			name: "wpa_supplicant networks",
+			input: `ctrl_interface=DIR=/var/run/wpa_supplicant GROUP=netdev
+update_config=1
+country=DE
+
+network={
+	ssid="Home"
+	psk="password123"
+	key_mgmt=WPA-PSK SAE
+}
+
+# Hidden WPA3 network with a hex SSID
+network={
+	ssid=4f6666696365
+	sae_password="correct horse"
+	key_mgmt=SAE
+	scan_ssid=1
+}
+
+network={
+	ssid="Raw"
+	psk=0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+}
+
+network={
+	ssid=P"Caf\xc3\xa9"
+	key_mgmt=NONE
+}
+`,
+			expected: []WiFi{
+				{SSID: "Home", Password: "password123", Encryption: WPA},
+				{SSID: "Office", Password: "correct horse", Encryption: SAE, Hidden: true},
+				{SSID: "Raw", Password: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", Encryption: WPA},
+				{SSID: "Café", Encryption: NoPass},
+			},
+		},
+		{
+			name: "wpa_supplicant EAP",
+			input: `network={
+	ssid="Corp"
+	key_mgmt=WPA-EAP
+	eap=TTLS
+	identity="bob"
+	anonymous_identity="anon@corp"
+	password="secret"
+	phase2="auth=PAP"
+}
+`,
+			expected: []WiFi{{
+				SSID: "Corp", Password: "secret", Encryption: WPA2EAP,
+				EAPMethod: "TTLS", Phase2: "PAP", AnonymousIdentity: "anon@corp", Identity: "bob",
+			}},
+		},
+		{
+			name: "wpa_supplicant WEP",
+			input: `network={
+	ssid="Old"
+	key_mgmt=NONE
+	wep_key0="abcde"
+}
+`,
+			expected: []WiFi{{SSID: "Old", Password: "abcde", Encryption: WEP}},
+		},
+		{
+			name:     "NT hash password",
+			input:    "network={\n\tssid=\"Corp\"\n\tkey_mgmt=WPA-EAP\n\teap=PEAP\n\tpassword=hash:0123456789abcdef\n}\n",
+			hasError: true,
+		},
+		{name: "unterminated block", input: "network={\n\tssid=\"Home\"\n", hasError: true},
+		{name: "no ssid", input: "network={\n\tpsk=\"password123\"\n}\n", hasError: true},
+		{name: "ethernet connection", input: "[connection]\nid=Wired\ntype=ethernet\n", hasError: true},
+		{name: "unsupported key management", input: "[wifi]\nssid=X\n[wifi-security]\nkey-mgmt=wpa-none\n", hasError: true},
+		{name: "not a config", input: "hello", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseWiFiConfig([]byte(tt.input))
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseWiFiConfig() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWiFiConfig() unexpected error: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("ParseWiFiConfig() returned %d networks, want %d", len(result), len(tt.expected))
+			}
+			for i, w := range result {
+				if *w != tt.expected[i] {
+					t.Errorf("network %d = %+v, want %+v", i, *w, tt.expected[i])
+				}
+			}
+		})
+	}
+}
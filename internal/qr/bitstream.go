@@ -0,0 +1,427 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Segment mode indicators
+const (
+	modeTerminator   = 0x0
+	modeNumeric      = 0x1
+	modeAlphanumeric = 0x2
+	modeStructured   = 0x3
+	modeByte         = 0x4
+	modeFNC1First    = 0x5
+	modeECI          = 0x7
+	modeKanji        = 0x8
+	modeFNC1Second   = 0x9
+)
+
+// alphanumericCharset lists the characters of alphanumeric mode in code order
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// charCountBits returns the width of the character count field for a
+// segment mode in a symbol of the given version. Micro QR versions M1 to
+// M4 are passed as -1 to -4 and give 0 for modes they cannot hold.
+func charCountBits(mode, version int) int {
+	if version < 0 {
+		return microCountBits(mode, -version)
+	}
+	group := 0
+	switch {
+	case version >= 27:
+		group = 2
+	case version >= 10:
+		group = 1
+	}
+	switch mode {
+	case modeNumeric:
+		return [3]int{10, 12, 14}[group]
+	case modeAlphanumeric:
+		return [3]int{9, 11, 13}[group]
+	case modeByte:
+		return [3]int{8, 16, 16}[group]
+	default:
+		return [3]int{8, 10, 12}[group]
+	}
+}
+
+// errFormat is returned when the format or version information of a
+// sampled symbol cannot be read
+var errFormat = errors.New("unreadable format information")
+
+// readFormat reads the level and mask from the two copies of the format
+// information, accepting up to three bit errors
+func readFormat(m [][]bool) (ErrorCorrectionLevel, int, error) {
+	dim := len(m)
+	bit := func(x, y int) uint32 {
+		if m[y][x] {
+			return 1
+		}
+		return 0
+	}
+
+	var first, second uint32
+	for x := 0; x <= 5; x++ {
+		first = first<<1 | bit(x, 8)
+	}
+	first = first<<1 | bit(7, 8)
+	first = first<<1 | bit(8, 8)
+	first = first<<1 | bit(8, 7)
+	for y := 5; y >= 0; y-- {
+		first = first<<1 | bit(8, y)
+	}
+	for y := dim - 1; y >= dim-7; y-- {
+		second = second<<1 | bit(8, y)
+	}
+	for x := dim - 8; x < dim; x++ {
+		second = second<<1 | bit(x, 8)
+	}
+
+	best, bestLevel, bestMask := 4, LevelM, 0
+	for level := LevelL; level <= LevelH; level++ {
+		for mask := 0; mask < 8; mask++ {
+			want := formatBits(level, mask)
+			for _, got := range []uint32{first, second} {
+				if d := bits.OnesCount32(got ^ want); d < best {
+					best, bestLevel, bestMask = d, level, mask
+				}
+			}
+		}
+	}
+	if best > 3 {
+		return 0, 0, errFormat
+	}
+	return bestLevel, bestMask, nil
+}
+
+// readVersion reads the version information of symbols of version 7 and
+// up, accepting up to three bit errors. Smaller symbols take their
+// version from the dimension.
+func readVersion(m [][]bool) (int, error) {
+	dim := len(m)
+	provisional := (dim - 17) / 4
+	if provisional <= 6 {
+		return provisional, nil
+	}
+
+	var topRight, bottomLeft uint32
+	for y := 5; y >= 0; y-- {
+		for x := dim - 9; x >= dim-11; x-- {
+			topRight <<= 1
+			if m[y][x] {
+				topRight |= 1
+			}
+		}
+	}
+	for x := 5; x >= 0; x-- {
+		for y := dim - 9; y >= dim-11; y-- {
+			bottomLeft <<= 1
+			if m[y][x] {
+				bottomLeft |= 1
+			}
+		}
+	}
+
+	best, version := 4, 0
+	for v := 7; v <= 40; v++ {
+		want := versionBits(v)
+		for _, got := range []uint32{topRight, bottomLeft} {
+			if d := bits.OnesCount32(got ^ want); d < best {
+				best, version = d, v
+			}
+		}
+	}
+	if best > 3 || symbolSize(version) != dim {
+		return 0, errFormat
+	}
+	return version, nil
+}
+
+// readCodewords unmasks the data modules and reads them in the zigzag
+// placement order
+func readCodewords(m [][]bool, version, mask int) []byte {
+	dim := len(m)
+	fn := functionPattern(version)
+	codewords := make([]byte, 0, totalCodewords(version))
+
+	var current byte
+	count := 0
+	up := true
+	for right := dim - 1; right > 0; right -= 2 {
+		// The vertical timing pattern shifts the column pairs left by one
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < dim; i++ {
+			y := i
+			if up {
+				y = dim - 1 - i
+			}
+			for x := right; x > right-2; x-- {
+				if fn[y][x] {
+					continue
+				}
+				current <<= 1
+				if m[y][x] != maskBit(mask, y, x) {
+					current |= 1
+				}
+				count++
+				if count == 8 {
+					codewords = append(codewords, current)
+					current, count = 0, 0
+				}
+			}
+		}
+		up = !up
+	}
+	return codewords
+}
+
+// correctCodewords splits interleaved codewords into blocks, repairs each
+// block and returns the data codewords in order
+func correctCodewords(codewords []byte, version int, level ErrorCorrectionLevel) ([]byte, error) {
+	ec := versions[version-1].ec[level]
+
+	var blocks [][]byte
+	var dataLens []int
+	for _, g := range ec.groups {
+		for i := 0; i < g.count; i++ {
+			blocks = append(blocks, make([]byte, 0, g.data+ec.ecPerBlock))
+			dataLens = append(dataLens, g.data)
+		}
+	}
+
+	// Data codewords are interleaved column by column, with the longer
+	// blocks contributing one extra codeword at the end
+	pos := 0
+	longest := dataLens[len(dataLens)-1]
+	for i := 0; i < longest; i++ {
+		for b := range blocks {
+			if i < dataLens[b] {
+				blocks[b] = append(blocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+	for i := 0; i < ec.ecPerBlock; i++ {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], codewords[pos])
+			pos++
+		}
+	}
+
+	data := make([]byte, 0, ec.dataCodewords())
+	for b, block := range blocks {
+		if _, err := rsCorrect(block, ec.ecPerBlock); err != nil {
+			return nil, err
+		}
+		data = append(data, block[:dataLens[b]]...)
+	}
+	return data, nil
+}
+
+// bitReader reads big-endian bit fields from a byte slice
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) available() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *bitReader) read(n int) (int, error) {
+	if n > r.available() {
+		return 0, errors.New("data ends in the middle of a segment")
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(r.data[r.pos/8]>>(7-r.pos%8)&1)
+		r.pos++
+	}
+	return v, nil
+}
+
+// parseSegments decodes the data codewords of a symbol into text. Micro
+// QR versions are passed as -1 to -4.
+func parseSegments(data []byte, version int) (string, error) {
+	r := &bitReader{data: data}
+	var b strings.Builder
+	eci := -1
+
+	for r.available() >= modeBits(version) {
+		mode, _ := r.read(modeBits(version))
+		if version < 0 {
+			mode = microModes[mode]
+		}
+		switch mode {
+		case modeTerminator:
+			return b.String(), nil
+		case modeFNC1First:
+			continue
+		case modeFNC1Second:
+			if _, err := r.read(8); err != nil {
+				return "", err
+			}
+			continue
+		case modeStructured:
+			// Sequence number and parity; each symbol decodes on its own
+			if _, err := r.read(16); err != nil {
+				return "", err
+			}
+			continue
+		case modeECI:
+			v, err := readECI(r)
+			if err != nil {
+				return "", err
+			}
+			eci = v
+			continue
+		}
+
+		count, err := r.read(charCountBits(mode, version))
+		if version < 0 && (err != nil || (mode == modeNumeric && count == 0)) {
+			// Micro QR terminators read as an empty numeric segment,
+			// cut short when the symbol is full
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch mode {
+		case modeNumeric:
+			err = readNumeric(r, count, &b)
+		case modeAlphanumeric:
+			err = readAlphanumeric(r, count, &b)
+		case modeByte:
+			err = readBytes(r, count, eci, &b)
+		case modeKanji:
+			err = readKanji(r, count, &b)
+		default:
+			err = fmt.Errorf("unknown segment mode %d", mode)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// readECI reads an ECI designator of one to three bytes
+func readECI(r *bitReader) (int, error) {
+	first, err := r.read(8)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case first&0x80 == 0:
+		return first, nil
+	case first&0xc0 == 0x80:
+		rest, err := r.read(8)
+		return (first&0x3f)<<8 | rest, err
+	case first&0xe0 == 0xc0:
+		rest, err := r.read(16)
+		return (first&0x1f)<<16 | rest, err
+	default:
+		return 0, fmt.Errorf("invalid ECI designator 0x%02x", first)
+	}
+}
+
+func readNumeric(r *bitReader, count int, b *strings.Builder) error {
+	for count > 0 {
+		digits, width := min(count, 3), [4]int{0, 4, 7, 10}[min(count, 3)]
+		v, err := r.read(width)
+		if err != nil {
+			return err
+		}
+		s := fmt.Sprintf("%0*d", digits, v)
+		if len(s) != digits {
+			return fmt.Errorf("invalid numeric group %d", v)
+		}
+		b.WriteString(s)
+		count -= digits
+	}
+	return nil
+}
+
+func readAlphanumeric(r *bitReader, count int, b *strings.Builder) error {
+	for count >= 2 {
+		v, err := r.read(11)
+		if err != nil {
+			return err
+		}
+		if v >= 45*45 {
+			return fmt.Errorf("invalid alphanumeric pair %d", v)
+		}
+		b.WriteByte(alphanumericCharset[v/45])
+		b.WriteByte(alphanumericCharset[v%45])
+		count -= 2
+	}
+	if count == 1 {
+		v, err := r.read(6)
+		if err != nil {
+			return err
+		}
+		if v >= 45 {
+			return fmt.Errorf("invalid alphanumeric character %d", v)
+		}
+		b.WriteByte(alphanumericCharset[v])
+	}
+	return nil
+}
+
+// readKanji reads a kanji segment, 13 bits per Shift-JIS character
+func readKanji(r *bitReader, count int, b *strings.Builder) error {
+	raw := make([]byte, 0, 2*count)
+	for i := 0; i < count; i++ {
+		v, err := r.read(13)
+		if err != nil {
+			return err
+		}
+		code := unpackKanji(v)
+		raw = append(raw, code[0], code[1])
+	}
+	text, err := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+	if err != nil {
+		return fmt.Errorf("invalid kanji segment: %w", err)
+	}
+	b.Write(text)
+	return nil
+}
+
+// readBytes reads a byte segment in the character set of the ECI. Without
+// one the bytes are taken as UTF-8 when valid and ISO-8859-1 otherwise,
+// which is what most encoders produce in practice.
+func readBytes(r *bitReader, count, eci int, b *strings.Builder) error {
+	raw := make([]byte, count)
+	for i := range raw {
+		v, err := r.read(8)
+		if err != nil {
+			return err
+		}
+		raw[i] = byte(v)
+	}
+
+	enc := eciEncoding(eci)
+	if enc == nil && eci != 26 && !utf8.Valid(raw) {
+		enc = charmap.ISO8859_1
+	}
+	if enc == nil {
+		b.Write(raw)
+		return nil
+	}
+	text, err := enc.NewDecoder().Bytes(raw)
+	if err != nil {
+		return fmt.Errorf("invalid %d-byte segment for ECI %d: %w", count, eci, err)
+	}
+	b.Write(text)
+	return nil
+}
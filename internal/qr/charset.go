@@ -0,0 +1,212 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Charset is the character set byte segments are encoded in, announced to
+// scanners by an ECI (Extended Channel Interpretation) header
+type Charset int
+
+const (
+	CharsetAuto     Charset = iota // No ECI for ASCII, UTF-8 with ECI otherwise
+	CharsetUTF8                    // ECI 26
+	CharsetISO88591                // ECI 3
+	CharsetShiftJIS                // ECI 20
+	CharsetGB18030                 // ECI 32
+)
+
+// ParseCharset parses a character set name as accepted by --eci
+func ParseCharset(s string) (Charset, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return CharsetAuto, nil
+	case "utf-8", "utf8":
+		return CharsetUTF8, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return CharsetISO88591, nil
+	case "shift-jis", "shift_jis", "shiftjis", "sjis":
+		return CharsetShiftJIS, nil
+	case "gb18030":
+		return CharsetGB18030, nil
+	default:
+		return CharsetAuto, fmt.Errorf("invalid character set: %s (use auto, utf-8, iso-8859-1, shift-jis or gb18030)", s)
+	}
+}
+
+// String returns the character set name
+func (c Charset) String() string {
+	switch c {
+	case CharsetAuto:
+		return "auto"
+	case CharsetUTF8:
+		return "UTF-8"
+	case CharsetISO88591:
+		return "ISO-8859-1"
+	case CharsetShiftJIS:
+		return "Shift-JIS"
+	case CharsetGB18030:
+		return "GB18030"
+	default:
+		return fmt.Sprintf("Charset(%d)", int(c))
+	}
+}
+
+// eci returns the ECI assignment number of the character set
+func (c Charset) eci() int {
+	switch c {
+	case CharsetISO88591:
+		return 3
+	case CharsetShiftJIS:
+		return 20
+	case CharsetGB18030:
+		return 32
+	default:
+		return 26
+	}
+}
+
+func (c Charset) encoding() encoding.Encoding {
+	switch c {
+	case CharsetISO88591:
+		return charmap.ISO8859_1
+	case CharsetShiftJIS:
+		return japanese.ShiftJIS
+	case CharsetGB18030:
+		return simplifiedchinese.GB18030
+	default:
+		return nil
+	}
+}
+
+// eciEncoding returns the encoding of byte segments read after an ECI
+// designator, or nil for UTF-8 and unknown assignments. GB 2312 (29) and
+// GBK (31) are subsets of GB18030.
+func eciEncoding(eci int) encoding.Encoding {
+	switch eci {
+	case 1, 3:
+		return charmap.ISO8859_1
+	case 20:
+		return japanese.ShiftJIS
+	case 29, 31, 32:
+		return simplifiedchinese.GB18030
+	default:
+		return nil
+	}
+}
+
+// payload is content transcoded to the bytes of its byte segments, with
+// the ECI assignment announcing the character set or -1 for none. widths
+// holds the byte length of the character starting at each position and 0
+// inside a character, so segments never split one, and kanji the
+// character's Shift-JIS code when kanji mode can hold it.
+type payload struct {
+	data   []byte
+	widths []int
+	kanji  []uint16
+	modes  []int // Modes the segmentation chooses between
+	eci    int
+	// autoECI drops the ECI header when no byte segment needs it
+	autoECI bool
+}
+
+// newPayload transcodes content to charset for encoding in mode. It fails
+// on the first character the character set or mode cannot represent.
+func newPayload(content string, charset Charset, mode Mode) (payload, error) {
+	if content == "" {
+		return payload{}, errNoData
+	}
+
+	p := payload{modes: segmentModes, eci: charset.eci()}
+	if mode == ModeKanji {
+		p.modes = []int{modeKanji}
+	}
+	if charset == CharsetAuto {
+		p.eci, p.autoECI = -1, true
+		for i := 0; i < len(content); i++ {
+			if content[i] >= utf8.RuneSelf {
+				p.eci = CharsetUTF8.eci()
+				break
+			}
+		}
+	}
+	if charset != CharsetAuto && charset != CharsetUTF8 && !utf8.ValidString(content) {
+		return payload{}, fmt.Errorf("content is not valid UTF-8, cannot transcode to %s", charset)
+	}
+
+	var enc *encoding.Encoder
+	if e := charset.encoding(); e != nil {
+		enc = e.NewEncoder()
+	}
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		b := []byte(content[i : i+size])
+		i += size
+
+		kanji := kanjiCode(r)
+		if mode == ModeKanji && kanji == 0 {
+			return payload{}, fmt.Errorf("character %q cannot be encoded in kanji mode, which holds only double-byte Shift-JIS characters", r)
+		}
+		if enc != nil {
+			var err error
+			if b, err = enc.Bytes(b); err != nil {
+				return payload{}, fmt.Errorf("character %q cannot be encoded in %s", r, charset)
+			}
+		}
+
+		p.data = append(p.data, b...)
+		p.widths = append(p.widths, len(b))
+		p.kanji = append(p.kanji, kanji)
+		for range len(b) - 1 {
+			p.widths = append(p.widths, 0)
+			p.kanji = append(p.kanji, 0)
+		}
+	}
+	return p, nil
+}
+
+// segments returns the ECI header, if any, and the shortest segmentation
+// of the payload in a symbol of the given version
+func (p payload) segments(version int) []segment {
+	segs := segmentContent(p, version)
+	if p.eci < 0 || (p.autoECI && !needsECI(segs)) {
+		return segs
+	}
+	return append([]segment{eciSegment(p.eci)}, segs...)
+}
+
+// needsECI reports whether a byte segment holds non-ASCII bytes, which
+// scanners would otherwise have to guess the character set of
+func needsECI(segs []segment) bool {
+	for _, s := range segs {
+		if s.mode != modeByte {
+			continue
+		}
+		for _, c := range s.data {
+			if c >= utf8.RuneSelf {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eciSegment returns the ECI header for an assignment number, with the
+// one, two or three byte designator of ISO/IEC 18004 section 7.4.2.2
+func eciSegment(eci int) segment {
+	switch {
+	case eci < 1<<7:
+		return segment{mode: modeECI, data: []byte{byte(eci)}}
+	case eci < 1<<14:
+		return segment{mode: modeECI, data: []byte{0x80 | byte(eci>>8), byte(eci)}}
+	default:
+		return segment{mode: modeECI, data: []byte{0xc0 | byte(eci>>16), byte(eci >> 8), byte(eci)}}
+	}
+}
@@ -0,0 +1,140 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseCharset(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Charset
+		wantErr bool
+	}{
+		{"auto", CharsetAuto, false},
+		{"", CharsetAuto, false},
+		{"utf-8", CharsetUTF8, false},
+		{"UTF8", CharsetUTF8, false},
+		{"iso-8859-1", CharsetISO88591, false},
+		{"latin1", CharsetISO88591, false},
+		{"shift-jis", CharsetShiftJIS, false},
+		{"Shift_JIS", CharsetShiftJIS, false},
+		{"gb18030", CharsetGB18030, false},
+		{"ebcdic", CharsetAuto, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCharset(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCharset(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseCharset(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPayload(t *testing.T) {
+	tests := []struct {
+		content string
+		charset Charset
+		data    []byte
+		eci     int
+		wantErr bool
+	}{
+		{"Hello", CharsetAuto, []byte("Hello"), -1, false},
+		{"Grüße", CharsetAuto, []byte("Grüße"), 26, false},
+		{"Hello", CharsetUTF8, []byte("Hello"), 26, false},
+		{"Grüße", CharsetISO88591, []byte{'G', 'r', 0xfc, 0xdf, 'e'}, 3, false},
+		{"日本", CharsetShiftJIS, []byte{0x93, 0xfa, 0x96, 0x7b}, 20, false},
+		{"张三", CharsetGB18030, []byte{0xd5, 0xc5, 0xc8, 0xfd}, 32, false},
+		{"€5", CharsetISO88591, nil, 0, true},
+		{"😀", CharsetShiftJIS, nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content+"/"+tt.charset.String(), func(t *testing.T) {
+			p, err := newPayload(tt.content, tt.charset, ModeAuto)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(p.data, tt.data) || p.eci != tt.eci {
+				t.Errorf("newPayload() = % x ECI %d, want % x ECI %d", p.data, p.eci, tt.data, tt.eci)
+			}
+		})
+	}
+}
+
+func TestNewPayloadUnrepresentable(t *testing.T) {
+	_, err := newPayload("Preis: 5€", CharsetISO88591, ModeAuto)
+	if err == nil || !strings.Contains(err.Error(), `'€'`) || !strings.Contains(err.Error(), "ISO-8859-1") {
+		t.Errorf("newPayload() error = %v, want it to name '€' and ISO-8859-1", err)
+	}
+}
+
+func TestECISegment(t *testing.T) {
+	tests := []struct {
+		eci  int
+		want []byte
+	}{
+		{3, []byte{0x03}},
+		{26, []byte{0x1a}},
+		{900, []byte{0x83, 0x84}},
+		{100000, []byte{0xc1, 0x86, 0xa0}},
+	}
+
+	for _, tt := range tests {
+		s := eciSegment(tt.eci)
+		if s.mode != modeECI || !bytes.Equal(s.data, tt.want) {
+			t.Errorf("eciSegment(%d) = % x, want % x", tt.eci, s.data, tt.want)
+		}
+
+		w := &bitWriter{}
+		s.write(w, 1)
+		r := &bitReader{data: w.data}
+		if mode, _ := r.read(4); mode != modeECI {
+			t.Errorf("eciSegment(%d) mode = %d", tt.eci, mode)
+		}
+		if got, err := readECI(r); err != nil || got != tt.eci {
+			t.Errorf("readECI() = %d, %v, want %d", got, err, tt.eci)
+		}
+	}
+}
+
+func TestCharsetRoundTrip(t *testing.T) {
+	tests := []struct {
+		content string
+		charset Charset
+	}{
+		{"Hello World 12345", CharsetAuto},
+		{"Grüße aus Köln 50667", CharsetAuto},
+		{"Grüße aus Köln 50667", CharsetISO88591},
+		{"東京都千代田区 100-0001 TEL 0312345678", CharsetShiftJIS},
+		{"张三 13800138000", CharsetGB18030},
+		// Four byte GB18030 characters have digits as trail bytes
+		{"😀1234567890😀ABCDEF", CharsetGB18030},
+		{"BEGIN:VCARD\nFN:张伟\nTEL:+8613912345678\nEND:VCARD", CharsetUTF8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content+"/"+tt.charset.String(), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ECI = tt.charset
+			code, err := NewGenerator(opts).Generate(tt.content)
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			content, _, _, _ := decodeSymbol(t, code.symbol)
+			if content != tt.content {
+				t.Errorf("decoded %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
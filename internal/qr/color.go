@@ -0,0 +1,121 @@
+package qr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Contrast thresholds (WCAG contrast ratio, 1-21) for scannable codes
+const (
+	MinContrast  = 2.0 // Below this, codes are refused as unscannable
+	WarnContrast = 3.0 // Below this, many scanners struggle
+)
+
+// namedColors maps the supported colour names to their values
+var namedColors = map[string]color.NRGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"lime":    {0x00, 0xff, 0x00, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"navy":    {0x00, 0x00, 0x80, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+	"cyan":    {0x00, 0xff, 0xff, 0xff},
+	"magenta": {0xff, 0x00, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"maroon":  {0x80, 0x00, 0x00, 0xff},
+	"olive":   {0x80, 0x80, 0x00, 0xff},
+	"teal":    {0x00, 0x80, 0x80, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+	"brown":   {0xa5, 0x2a, 0x2a, 0xff},
+	"pink":    {0xff, 0xc0, 0xcb, 0xff},
+}
+
+// ParseColor parses a colour given as hex (#RGB, #RRGGBB, #RRGGBBAA),
+// a colour name such as "navy", or "transparent"
+func ParseColor(s string) (color.Color, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if lower == "transparent" || lower == "none" {
+		return color.NRGBA{}, nil
+	}
+	if c, ok := namedColors[lower]; ok {
+		return c, nil
+	}
+
+	digits := strings.TrimPrefix(lower, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	if len(digits) == 8 {
+		if b, err := hex.DecodeString(digits); err == nil {
+			return color.NRGBA{R: b[0], G: b[1], B: b[2], A: b[3]}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid color: %s (use #RRGGBB, #RRGGBBAA, a color name, or transparent)", s)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colours,
+// from 1 (identical) to 21 (black on white). Translucent colours are
+// composited over white first.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// CheckContrast reports whether a foreground/background pair is likely
+// to scan. It returns an error for combinations that are almost certainly
+// unreadable, and a warning for risky ones such as low contrast or
+// inverted (light on dark) codes.
+func CheckContrast(fg, bg color.Color) (string, error) {
+	if _, _, _, a := fg.RGBA(); a == 0 {
+		return "", fmt.Errorf("foreground color cannot be transparent")
+	}
+
+	ratio := ContrastRatio(fg, bg)
+	if ratio < MinContrast {
+		return "", fmt.Errorf("contrast ratio %.1f:1 between foreground and background is too low to scan (need at least %.1f:1)", ratio, MinContrast)
+	}
+
+	var warnings []string
+	if ratio < WarnContrast {
+		warnings = append(warnings, fmt.Sprintf("low contrast ratio %.1f:1, some scanners may fail", ratio))
+	}
+	if luminance(fg) > luminance(bg) {
+		warnings = append(warnings, "foreground is lighter than background, some scanners cannot read inverted codes")
+	}
+	if _, _, _, a := bg.RGBA(); a == 0 {
+		warnings = append(warnings, "transparent background, contrast depends on what the code is placed on")
+	}
+
+	return strings.Join(warnings, "; "), nil
+}
+
+// luminance returns the WCAG relative luminance of c composited over white
+func luminance(c color.Color) float64 {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	alpha := float64(nrgba.A) / 0xff
+
+	channel := func(v uint8) float64 {
+		s := (float64(v)/0xff)*alpha + (1 - alpha)
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(nrgba.R) + 0.7152*channel(nrgba.G) + 0.0722*channel(nrgba.B)
+}
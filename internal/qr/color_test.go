@@ -0,0 +1,99 @@
+package qr
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected color.NRGBA
+		hasError bool
+	}{
+		{"#000000", color.NRGBA{0, 0, 0, 0xff}, false},
+		{"#FF8000", color.NRGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"ff8000", color.NRGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"#f80", color.NRGBA{0xff, 0x88, 0x00, 0xff}, false},
+		{"#11223380", color.NRGBA{0x11, 0x22, 0x33, 0x80}, false},
+		{"navy", color.NRGBA{0x00, 0x00, 0x80, 0xff}, false},
+		{"White", color.NRGBA{0xff, 0xff, 0xff, 0xff}, false},
+		{"transparent", color.NRGBA{}, false},
+		{"#12345", color.NRGBA{}, true},
+		{"#gggggg", color.NRGBA{}, true},
+		{"chartreuse-ish", color.NRGBA{}, true},
+		{"", color.NRGBA{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseColor(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseColor(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseColor(%q) unexpected error: %v", tt.input, err)
+			}
+			if got := color.NRGBAModel.Convert(result).(color.NRGBA); got != tt.expected {
+				t.Errorf("ParseColor(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	if r := ContrastRatio(color.Black, color.White); math.Abs(r-21) > 0.01 {
+		t.Errorf("ContrastRatio(black, white) = %v, want 21", r)
+	}
+	if r := ContrastRatio(color.White, color.Black); math.Abs(r-21) > 0.01 {
+		t.Errorf("ContrastRatio(white, black) = %v, want 21", r)
+	}
+	if r := ContrastRatio(color.White, color.White); r != 1 {
+		t.Errorf("ContrastRatio(white, white) = %v, want 1", r)
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	tests := []struct {
+		name     string
+		fg, bg   string
+		hasError bool
+		warns    bool
+	}{
+		{"black on white", "black", "white", false, false},
+		{"navy on white", "navy", "#ffffff", false, false},
+		{"light on light", "silver", "white", true, false},
+		{"yellow on white", "yellow", "white", true, false},
+		{"inverted", "white", "black", false, true},
+		{"low contrast", "#a0a0a0", "white", false, true},
+		{"transparent background", "black", "transparent", false, true},
+		{"transparent foreground", "transparent", "white", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fg, _ := ParseColor(tt.fg)
+			bg, _ := ParseColor(tt.bg)
+			warning, err := CheckContrast(fg, bg)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("CheckContrast(%s, %s) expected error, got nil", tt.fg, tt.bg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckContrast(%s, %s) unexpected error: %v", tt.fg, tt.bg, err)
+			}
+			if tt.warns && warning == "" {
+				t.Errorf("CheckContrast(%s, %s) expected warning", tt.fg, tt.bg)
+			}
+			if !tt.warns && warning != "" {
+				t.Errorf("CheckContrast(%s, %s) unexpected warning: %s", tt.fg, tt.bg, warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// ErrNotFound is returned by Decode when an image contains no readable
+// QR code
+var ErrNotFound = errors.New("no QR code found")
+
+// Result is one QR code found in an image
+type Result struct {
+	Content string
+	Version int
+	Level   ErrorCorrectionLevel
+	// Corners of the symbol (excluding quiet zone) in image coordinates:
+	// top-left, top-right, bottom-right, bottom-left as printed
+	Corners [4]image.Point
+}
+
+// Bounds returns the smallest rectangle containing the symbol
+func (r Result) Bounds() image.Rectangle {
+	b := image.Rectangle{Min: r.Corners[0], Max: r.Corners[0]}
+	for _, p := range r.Corners[1:] {
+		b.Min.X, b.Min.Y = min(b.Min.X, p.X), min(b.Min.Y, p.Y)
+		b.Max.X, b.Max.Y = max(b.Max.X, p.X), max(b.Max.Y, p.Y)
+	}
+	return b
+}
+
+// Decode finds and decodes every QR code in img. Results are ordered top
+// to bottom, then left to right. Light-on-dark codes are tried when no
+// regular code is found.
+func Decode(img image.Image) ([]Result, error) {
+	bits := binarize(img)
+	results := decodeAll(bits)
+	if len(results) == 0 {
+		results = decodeAll(bits.inverted())
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+
+	origin := img.Bounds().Min
+	for i := range results {
+		for j := range results[i].Corners {
+			results[i].Corners[j] = results[i].Corners[j].Add(origin)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Bounds(), results[j].Bounds()
+		if a.Min.Y != b.Min.Y {
+			return a.Min.Y < b.Min.Y
+		}
+		return a.Min.X < b.Min.X
+	})
+	return results, nil
+}
+
+// Verify rasterises code at size pixels, as PNG output would, decodes it
+// and checks that it reads back as content. It catches payloads that do
+// not survive encoding as well as colours, styles and logos that make the
+// code unreadable.
+func Verify(code *Code, content string, size int) error {
+	if code.Micro {
+		return verifyMicro(code, content, size)
+	}
+	results, err := Decode(renderImage(code, size))
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if got := results[0].Content; got != content {
+		return fmt.Errorf("verification failed: code reads back as %q, want %q", got, content)
+	}
+	return nil
+}
+
+// verifyMicro checks a Micro QR code by sampling the rendered image at
+// the module centres, which are known, since Decode only finds symbols
+// with three finder patterns
+func verifyMicro(code *Code, content string, size int) error {
+	bits := binarize(renderImage(code, size))
+	dim, margin := len(code.symbol), code.opts.Margin
+	scale := float64(bits.w) / float64(dim+2*margin)
+
+	// The finder's centre is dark in every eye style, so a light one means
+	// light-on-dark colours
+	centre := int((float64(margin) + 3.5) * scale)
+	invert := !bits.at(centre, centre)
+	m := newGrid(dim)
+	for y := range m {
+		for x := range m[y] {
+			px := int((float64(x+margin) + 0.5) * scale)
+			py := int((float64(y+margin) + 0.5) * scale)
+			m[y][x] = bits.at(px, py) != invert
+		}
+	}
+
+	got, _, _, err := decodeMicro(m)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if got != content {
+		return fmt.Errorf("verification failed: code reads back as %q, want %q", got, content)
+	}
+	return nil
+}
+
+// decodeAll tries every plausible finder pattern triple. A pattern that
+// has been part of a decoded symbol is not reused.
+func decodeAll(bits *bitImage) []Result {
+	var results []Result
+	used := make(map[finderPattern]bool)
+
+	for _, t := range selectTriples(findFinders(bits)) {
+		if used[t[0]] || used[t[1]] || used[t[2]] {
+			continue
+		}
+		for _, dim := range candidateDimensions(t) {
+			r, ok := decodeAt(bits, t, dim)
+			if !ok {
+				continue
+			}
+			used[t[0]], used[t[1]], used[t[2]] = true, true, true
+			results = append(results, r)
+			break
+		}
+	}
+	return results
+}
+
+// decodeAt samples and decodes a symbol of width dim located by t
+func decodeAt(bits *bitImage, t finderTriple, dim int) (Result, bool) {
+	h, ok := locate(bits, t, dim)
+	if !ok {
+		return Result{}, false
+	}
+	m, ok := sampleGrid(bits, h, dim)
+	if !ok {
+		return Result{}, false
+	}
+
+	content, version, level, err := decodeMatrix(m)
+	if err != nil {
+		// Mirrored symbols read correctly once transposed
+		if content, version, level, err = decodeMatrix(transpose(m)); err != nil {
+			return Result{}, false
+		}
+	}
+
+	r := Result{Content: content, Version: version, Level: level}
+	d := float64(dim)
+	for i, c := range [4][2]float64{{0, 0}, {d, 0}, {d, d}, {0, d}} {
+		x, y := h.apply(c[0], c[1])
+		r.Corners[i] = image.Pt(int(math.Round(x)), int(math.Round(y)))
+	}
+	return r, true
+}
+
+// decodeMatrix decodes a sampled symbol, m[y][x] being true for dark
+// modules
+func decodeMatrix(m [][]bool) (string, int, ErrorCorrectionLevel, error) {
+	level, mask, err := readFormat(m)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	version, err := readVersion(m)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if symbolSize(version) != len(m) {
+		return "", 0, 0, fmt.Errorf("version %d does not match symbol width %d", version, len(m))
+	}
+
+	data, err := correctCodewords(readCodewords(m, version, mask), version, level)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	content, err := parseSegments(data, version)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return content, version, level, nil
+}
+
+func transpose(m [][]bool) [][]bool {
+	t := make([][]bool, len(m))
+	for y := range t {
+		t[y] = make([]bool, len(m))
+		for x := range t[y] {
+			t[y][x] = m[x][y]
+		}
+	}
+	return t
+}
@@ -0,0 +1,340 @@
+package qr
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+// testImage renders content with opts at size pixels
+func testImage(t *testing.T, content string, opts Options, size int) image.Image {
+	t.Helper()
+	code, err := NewGenerator(opts).Generate(content)
+	if err != nil {
+		t.Fatalf("Generate(%q) error: %v", content, err)
+	}
+	return renderImage(code, size)
+}
+
+func TestDecode(t *testing.T) {
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+
+	tests := []struct {
+		name    string
+		content string
+		level   ErrorCorrectionLevel
+		size    int
+		version int
+	}{
+		{"numeric", "0123456789012345", LevelM, 256, 1},
+		{"alphanumeric", "HELLO WORLD $%*+-./:", LevelQ, 256, 2},
+		{"url", "https://example.com/path?q=1", LevelL, 256, 2},
+		{"utf-8", "Grüße, 世界", LevelH, 256, 3},
+		{"one pixel per module", "tiny", LevelM, 1, 1},
+		{"version info", strings.Repeat("x", 150), LevelM, 400, 8},
+		{"large", long, LevelL, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Level = tt.level
+			img := testImage(t, tt.content, opts, tt.size)
+
+			results, err := Decode(img)
+			if err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Decode() found %d codes, want 1", len(results))
+			}
+			r := results[0]
+			if r.Content != tt.content {
+				t.Errorf("Decode() content = %q, want %q", r.Content, tt.content)
+			}
+			if r.Level != tt.level {
+				t.Errorf("Decode() level = %v, want %v", r.Level, tt.level)
+			}
+			if tt.version != 0 && r.Version != tt.version {
+				t.Errorf("Decode() version = %d, want %d", r.Version, tt.version)
+			}
+		})
+	}
+}
+
+func TestDecodeCorners(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ModuleSize = 10
+	img := testImage(t, "corners", opts, 0)
+
+	results, err := Decode(img)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+
+	// Version 1 is 21 modules wide, inside a 4 module quiet zone
+	want := image.Rect(40, 40, 250, 250)
+	if got := results[0].Bounds(); got != want {
+		t.Errorf("Decode() bounds = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeMultiple(t *testing.T) {
+	canvas := image.NewRGBA(image.Rect(0, 0, 900, 500))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+
+	contents := []string{"first", "second code", "THIRD 333"}
+	origins := []image.Point{{20, 30}, {480, 10}, {300, 250}}
+	sizes := []int{250, 300, 220}
+	for i, content := range contents {
+		img := testImage(t, content, DefaultOptions(), sizes[i])
+		r := image.Rect(0, 0, sizes[i], sizes[i]).Add(origins[i])
+		draw.Draw(canvas, r, img, image.Point{}, draw.Src)
+	}
+
+	results, err := Decode(canvas)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if len(results) != len(contents) {
+		t.Fatalf("Decode() found %d codes, want %d", len(results), len(contents))
+	}
+
+	// Ordered top to bottom
+	want := []string{"second code", "first", "THIRD 333"}
+	for i, r := range results {
+		if r.Content != want[i] {
+			t.Errorf("Decode() result %d = %q, want %q", i, r.Content, want[i])
+		}
+	}
+}
+
+func TestDecodeTransformed(t *testing.T) {
+	const content = "https://example.com/rotated"
+	src := testImage(t, content, DefaultOptions(), 290)
+	b := src.Bounds()
+
+	transforms := map[string]func(x, y int) (int, int){
+		"rotated 90":  func(x, y int) (int, int) { return b.Dy() - 1 - y, x },
+		"rotated 180": func(x, y int) (int, int) { return b.Dx() - 1 - x, b.Dy() - 1 - y },
+		"mirrored":    func(x, y int) (int, int) { return b.Dx() - 1 - x, y },
+	}
+
+	for name, transform := range transforms {
+		t.Run(name, func(t *testing.T) {
+			dst := image.NewRGBA(b)
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					dx, dy := transform(x, y)
+					dst.Set(dx, dy, src.At(x, y))
+				}
+			}
+
+			results, err := Decode(dst)
+			if err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if results[0].Content != content {
+				t.Errorf("Decode() content = %q, want %q", results[0].Content, content)
+			}
+		})
+	}
+}
+
+func TestDecodeSkewed(t *testing.T) {
+	const content = "photographed at an angle"
+	src := testImage(t, content, DefaultOptions(), 300)
+
+	// Map the output quadrilateral back onto the flat source image
+	h, ok := solveHomography(
+		[4][2]float64{{60, 40}, {420, 90}, {380, 430}, {30, 360}},
+		[4][2]float64{{0, 0}, {300, 0}, {300, 300}, {0, 300}},
+	)
+	if !ok {
+		t.Fatal("solveHomography() failed")
+	}
+	dst := image.NewGray(image.Rect(0, 0, 480, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 480; x++ {
+			sx, sy := h.apply(float64(x)+0.5, float64(y)+0.5)
+			c := color.Color(color.White)
+			if p := image.Pt(int(sx), int(sy)); sx >= 0 && sy >= 0 && p.In(src.Bounds()) {
+				c = src.At(p.X, p.Y)
+			}
+			dst.Set(x, y, c)
+		}
+	}
+
+	results, err := Decode(dst)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if results[0].Content != content {
+		t.Errorf("Decode() content = %q, want %q", results[0].Content, content)
+	}
+}
+
+func TestDecodeStyled(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*Options)
+	}{
+		{"inverted", func(o *Options) { o.ForegroundColor, o.BackgroundColor = color.White, color.Black }},
+		{"colored", func(o *Options) { o.ForegroundColor = color.NRGBA{0x00, 0x00, 0x80, 0xff} }},
+		{"transparent background", func(o *Options) { o.BackgroundColor = color.Transparent }},
+		{"dots", func(o *Options) { o.ModuleStyle = StyleDot }},
+		{"connected circles", func(o *Options) { o.ModuleStyle, o.EyeStyle = StyleConnected, EyeCircle }},
+		{"logo", func(o *Options) { o.Logo = testLogo(40) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const content = "styled content 12345"
+			opts := DefaultOptions()
+			tt.edit(&opts)
+			results, err := Decode(testImage(t, content, opts, 400))
+			if err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if results[0].Content != content {
+				t.Errorf("Decode() content = %q, want %q", results[0].Content, content)
+			}
+		})
+	}
+}
+
+func TestDecodeDamaged(t *testing.T) {
+	const content = "damaged but readable"
+	opts := DefaultOptions()
+	opts.Level = LevelH
+	opts.ModuleSize = 8
+	img := testImage(t, content, opts, 0).(*image.Paletted)
+
+	// Scribble over a band of data modules in the middle of the symbol
+	for y := 100; y < 130; y++ {
+		for x := 80; x < 200; x++ {
+			img.SetColorIndex(x, y, uint8((x/8+y/8)%2))
+		}
+	}
+
+	results, err := Decode(img)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if results[0].Content != content {
+		t.Errorf("Decode() content = %q, want %q", results[0].Content, content)
+	}
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	blank := image.NewGray(image.Rect(0, 0, 200, 200))
+	draw.Draw(blank, blank.Bounds(), image.White, image.Point{}, draw.Src)
+	if _, err := Decode(blank); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Decode(blank) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ModuleStyle = StyleDot
+	code, err := NewGenerator(opts).Generate("WIFI:T:WPA;S:My\\;Net;P:p\\:w;;")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if err := Verify(code, "WIFI:T:WPA;S:My\\;Net;P:p\\:w;;", 256); err != nil {
+		t.Errorf("Verify() unexpected error: %v", err)
+	}
+	if err := Verify(code, "WIFI:T:WPA;S:My;Net;P:p:w;;", 256); err == nil {
+		t.Error("Verify() with different content expected error, got nil")
+	}
+
+	// A logo far larger than the level can recover breaks the code
+	code.opts.Logo = testLogo(40)
+	code.opts.LogoScale = 0.6
+	if err := Verify(code, "WIFI:T:WPA;S:My\\;Net;P:p\\:w;;", 256); err == nil {
+		t.Error("Verify() with oversized logo expected error, got nil")
+	}
+}
+
+func TestRSCorrect(t *testing.T) {
+	// Version 1-M encoding of "01234567" from ISO/IEC 18004 annex I
+	valid := []byte{
+		0x10, 0x20, 0x0c, 0x56, 0x61, 0x80, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11,
+		0xa5, 0x24, 0xd4, 0xc1, 0xed, 0x36, 0xc7, 0x87, 0x2c, 0x55,
+	}
+
+	tests := []struct {
+		name      string
+		errors    []int
+		corrected int
+		hasError  bool
+	}{
+		{"clean", nil, 0, false},
+		{"one error", []int{3}, 1, false},
+		{"error in EC codeword", []int{20}, 1, false},
+		{"five errors", []int{0, 4, 9, 15, 25}, 5, false},
+		{"six errors", []int{0, 1, 2, 3, 4, 5}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := append([]byte(nil), valid...)
+			for _, i := range tt.errors {
+				block[i] ^= 0x5a
+			}
+			n, err := rsCorrect(block, 10)
+			if tt.hasError {
+				if err == nil {
+					t.Error("rsCorrect() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rsCorrect() unexpected error: %v", err)
+			}
+			if n != tt.corrected {
+				t.Errorf("rsCorrect() corrected %d, want %d", n, tt.corrected)
+			}
+			if string(block) != string(valid) {
+				t.Errorf("rsCorrect() = %x, want %x", block, valid)
+			}
+		})
+	}
+}
+
+func TestVersionTable(t *testing.T) {
+	for v := 1; v <= 40; v++ {
+		// Raw data modules, from the symbol size minus function patterns
+		raw := (16*v+128)*v + 64
+		if v >= 2 {
+			n := v/7 + 2
+			raw -= (25*n-10)*n - 55
+		}
+		if v >= 7 {
+			raw -= 36
+		}
+
+		for level := LevelL; level <= LevelH; level++ {
+			ec := versions[v-1].ec[level]
+			if got := ec.dataCodewords() + ec.ecPerBlock*ec.blocks(); got != raw/8 {
+				t.Errorf("version %d-%v has %d codewords, want %d", v, level, got, raw/8)
+			}
+		}
+
+		// The function pattern must leave exactly the raw modules free
+		free := 0
+		for _, row := range functionPattern(v) {
+			for _, fn := range row {
+				if !fn {
+					free++
+				}
+			}
+		}
+		if free != raw {
+			t.Errorf("version %d has %d data modules, want %d", v, free, raw)
+		}
+	}
+}
@@ -0,0 +1,655 @@
+package qr
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// bitImage is a binarised image; dark holds one entry per pixel, row by row
+type bitImage struct {
+	w, h int
+	dark []bool
+}
+
+func (b *bitImage) at(x, y int) bool {
+	return b.dark[y*b.w+x]
+}
+
+// inverted returns a copy with dark and light swapped, for light-on-dark
+// codes
+func (b *bitImage) inverted() *bitImage {
+	inv := &bitImage{w: b.w, h: b.h, dark: make([]bool, len(b.dark))}
+	for i, d := range b.dark {
+		inv.dark[i] = !d
+	}
+	return inv
+}
+
+// grayscale returns the luma of every pixel, composited over white
+func grayscale(img image.Image) (lum []uint8, w, h int) {
+	bounds := img.Bounds()
+	w, h = bounds.Dx(), bounds.Dy()
+	lum = make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			// Premultiplied, so adding the missing alpha composites over white
+			r, g, b = r+0xffff-a, g+0xffff-a, b+0xffff-a
+			lum[y*w+x] = uint8((299*r + 587*g + 114*b) / 1000 >> 8)
+		}
+	}
+	return lum, w, h
+}
+
+// Binarisation block size and the minimum luma range for a block to be
+// considered to contain an edge
+const (
+	binBlock    = 8
+	binMinRange = 24
+)
+
+// binarize separates dark from light pixels. Large images use a local
+// threshold per 8x8 block, averaged over the surrounding 5x5 blocks, so
+// uneven lighting in photos does not wipe out parts of the code. Small
+// images use a single global threshold.
+func binarize(img image.Image) *bitImage {
+	lum, w, h := grayscale(img)
+	out := &bitImage{w: w, h: h, dark: make([]bool, w*h)}
+
+	if w < 5*binBlock || h < 5*binBlock {
+		t := otsuThreshold(lum)
+		for i, v := range lum {
+			out.dark[i] = v <= t
+		}
+		return out
+	}
+
+	bw := (w + binBlock - 1) / binBlock
+	bh := (h + binBlock - 1) / binBlock
+	black := make([]int, bw*bh)
+	for by := 0; by < bh; by++ {
+		y0 := min(by*binBlock, h-binBlock)
+		for bx := 0; bx < bw; bx++ {
+			x0 := min(bx*binBlock, w-binBlock)
+			sum, lo, hi := 0, 255, 0
+			for y := y0; y < y0+binBlock; y++ {
+				for _, v := range lum[y*w+x0 : y*w+x0+binBlock] {
+					sum += int(v)
+					lo = min(lo, int(v))
+					hi = max(hi, int(v))
+				}
+			}
+
+			avg := sum / (binBlock * binBlock)
+			if hi-lo <= binMinRange {
+				// A flat block is assumed to be background, unless its
+				// neighbours say it lies inside a dark area
+				avg = lo / 2
+				if bx > 0 && by > 0 {
+					neighbours := (black[(by-1)*bw+bx] + 2*black[by*bw+bx-1] + black[(by-1)*bw+bx-1]) / 4
+					if lo < neighbours {
+						avg = neighbours
+					}
+				}
+			}
+			black[by*bw+bx] = avg
+		}
+	}
+
+	for by := 0; by < bh; by++ {
+		y0 := min(by*binBlock, h-binBlock)
+		cy := min(max(by, 2), bh-3)
+		for bx := 0; bx < bw; bx++ {
+			x0 := min(bx*binBlock, w-binBlock)
+			cx := min(max(bx, 2), bw-3)
+			sum := 0
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					sum += black[(cy+dy)*bw+cx+dx]
+				}
+			}
+			t := uint8(sum / 25)
+			for y := y0; y < y0+binBlock; y++ {
+				for x := x0; x < x0+binBlock; x++ {
+					out.dark[y*w+x] = lum[y*w+x] <= t
+				}
+			}
+		}
+	}
+	return out
+}
+
+// otsuThreshold returns the luma that best splits the histogram in two
+func otsuThreshold(lum []uint8) uint8 {
+	var hist [256]int
+	for _, v := range lum {
+		hist[v]++
+	}
+	total, sum := len(lum), 0
+	for i, n := range hist {
+		sum += i * n
+	}
+
+	best, threshold := -1.0, uint8(127)
+	weight, weightedSum := 0, 0
+	for i, n := range hist {
+		weight += n
+		weightedSum += i * n
+		if weight == 0 || weight == total {
+			continue
+		}
+		meanLow := float64(weightedSum) / float64(weight)
+		meanHigh := float64(sum-weightedSum) / float64(total-weight)
+		between := float64(weight) * float64(total-weight) * (meanLow - meanHigh) * (meanLow - meanHigh)
+		if between > best {
+			best, threshold = between, uint8(i)
+		}
+	}
+	return threshold
+}
+
+// finderPattern is a candidate finder pattern centre, in pixels, with its
+// estimated module size and the number of scan lines that confirmed it
+type finderPattern struct {
+	x, y, size float64
+	count      int
+}
+
+// finderRatio reports whether run lengths match the 1:1:3:1:1 dark/light
+// profile of a finder pattern
+func finderRatio(runs [5]int) bool {
+	total := 0
+	for _, r := range runs {
+		if r == 0 {
+			return false
+		}
+		total += r
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	tolerance := module / 2
+	return math.Abs(module-float64(runs[0])) < tolerance &&
+		math.Abs(module-float64(runs[1])) < tolerance &&
+		math.Abs(3*module-float64(runs[2])) < 3*tolerance &&
+		math.Abs(module-float64(runs[3])) < tolerance &&
+		math.Abs(module-float64(runs[4])) < tolerance
+}
+
+// crossCheck measures the finder profile through (x, y) along the
+// direction (dx, dy) and returns the refined centre coordinate on that
+// axis, or NaN when the profile does not match. maxRun bounds the outer
+// runs and total is the profile width seen by the row scan.
+func crossCheck(img *bitImage, x, y, dx, dy, maxRun, total int) float64 {
+	var runs [5]int
+	inside := func(x, y int) bool { return x >= 0 && y >= 0 && x < img.w && y < img.h }
+
+	// Walk backwards through centre, light ring and outer dark ring
+	cx, cy := x, y
+	for inside(cx, cy) && img.at(cx, cy) {
+		runs[2]++
+		cx, cy = cx-dx, cy-dy
+	}
+	if !inside(cx, cy) {
+		return math.NaN()
+	}
+	for inside(cx, cy) && !img.at(cx, cy) && runs[1] <= maxRun {
+		runs[1]++
+		cx, cy = cx-dx, cy-dy
+	}
+	if !inside(cx, cy) || runs[1] > maxRun {
+		return math.NaN()
+	}
+	for inside(cx, cy) && img.at(cx, cy) && runs[0] <= maxRun {
+		runs[0]++
+		cx, cy = cx-dx, cy-dy
+	}
+	if runs[0] > maxRun {
+		return math.NaN()
+	}
+
+	// And forwards
+	cx, cy = x+dx, y+dy
+	for inside(cx, cy) && img.at(cx, cy) {
+		runs[2]++
+		cx, cy = cx+dx, cy+dy
+	}
+	if !inside(cx, cy) {
+		return math.NaN()
+	}
+	for inside(cx, cy) && !img.at(cx, cy) && runs[3] < maxRun {
+		runs[3]++
+		cx, cy = cx+dx, cy+dy
+	}
+	if !inside(cx, cy) || runs[3] >= maxRun {
+		return math.NaN()
+	}
+	for inside(cx, cy) && img.at(cx, cy) && runs[4] < maxRun {
+		runs[4]++
+		cx, cy = cx+dx, cy+dy
+	}
+	if runs[4] >= maxRun {
+		return math.NaN()
+	}
+
+	sum := runs[0] + runs[1] + runs[2] + runs[3] + runs[4]
+	if 5*abs(sum-total) >= 2*total || !finderRatio(runs) {
+		return math.NaN()
+	}
+	end := cx*dx + cy*dy
+	return float64(end-runs[4]-runs[3]) - float64(runs[2])/2
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// findFinders scans every row for the finder profile, confirms each hit
+// vertically and horizontally, and merges hits on the same pattern
+func findFinders(img *bitImage) []finderPattern {
+	var found []finderPattern
+
+	check := func(runs [5]int, row, end int) bool {
+		total := runs[0] + runs[1] + runs[2] + runs[3] + runs[4]
+		cx := float64(end-runs[4]-runs[3]) - float64(runs[2])/2
+		cy := crossCheck(img, int(cx), row, 0, 1, runs[2], total)
+		if math.IsNaN(cy) {
+			return false
+		}
+		cx = crossCheck(img, int(cx), int(cy), 1, 0, runs[2], total)
+		if math.IsNaN(cx) {
+			return false
+		}
+
+		size := float64(total) / 7
+		for i, f := range found {
+			if math.Abs(cy-f.y) <= size && math.Abs(cx-f.x) <= size {
+				if diff := math.Abs(size - f.size); diff <= 1 || diff <= f.size {
+					n := float64(f.count)
+					found[i] = finderPattern{
+						x:     (n*f.x + cx) / (n + 1),
+						y:     (n*f.y + cy) / (n + 1),
+						size:  (n*f.size + size) / (n + 1),
+						count: f.count + 1,
+					}
+					return true
+				}
+			}
+		}
+		found = append(found, finderPattern{x: cx, y: cy, size: size, count: 1})
+		return true
+	}
+
+	for y := 0; y < img.h; y++ {
+		var runs [5]int
+		state := 0
+		for x := 0; x < img.w; x++ {
+			if img.at(x, y) {
+				if state&1 == 1 {
+					state++
+				}
+				runs[state]++
+				continue
+			}
+			if state&1 == 1 {
+				runs[state]++
+				continue
+			}
+			if state < 4 {
+				state++
+				runs[state]++
+				continue
+			}
+			if finderRatio(runs) && check(runs, y, x) {
+				runs, state = [5]int{}, 0
+				continue
+			}
+			runs = [5]int{runs[2], runs[3], runs[4], 1, 0}
+			state = 3
+		}
+		if state == 4 && finderRatio(runs) {
+			check(runs, y, img.w)
+		}
+	}
+
+	// A single hit is usually noise; real patterns span several rows
+	confirmed := found[:0]
+	for _, f := range found {
+		if f.count >= 2 {
+			confirmed = append(confirmed, f)
+		}
+	}
+	return confirmed
+}
+
+// finderTriple is three finder patterns ordered bottom-left, top-left,
+// top-right
+type finderTriple [3]finderPattern
+
+func dist(a, b finderPattern) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+// orderTriple puts the pattern opposite the longest side at top-left, and
+// the other two so that they run clockwise
+func orderTriple(p [3]finderPattern) finderTriple {
+	d01, d12, d02 := dist(p[0], p[1]), dist(p[1], p[2]), dist(p[0], p[2])
+	var a, b, c finderPattern
+	switch {
+	case d12 >= d01 && d12 >= d02:
+		b, a, c = p[0], p[1], p[2]
+	case d02 >= d12 && d02 >= d01:
+		b, a, c = p[1], p[0], p[2]
+	default:
+		b, a, c = p[2], p[0], p[1]
+	}
+	if (c.x-b.x)*(a.y-b.y)-(c.y-b.y)*(a.x-b.x) < 0 {
+		a, c = c, a
+	}
+	return finderTriple{a, b, c}
+}
+
+// selectTriples returns every combination of three patterns that could
+// belong to one symbol, smallest first
+func selectTriples(patterns []finderPattern) []finderTriple {
+	similar := func(a, b finderPattern) bool {
+		return math.Abs(a.size-b.size) <= max(0.5, 0.4*min(a.size, b.size))
+	}
+
+	var triples []finderTriple
+	for i := 0; i < len(patterns); i++ {
+		for j := i + 1; j < len(patterns); j++ {
+			if !similar(patterns[i], patterns[j]) {
+				continue
+			}
+			for k := j + 1; k < len(patterns); k++ {
+				if !similar(patterns[i], patterns[k]) || !similar(patterns[j], patterns[k]) {
+					continue
+				}
+				t := orderTriple([3]finderPattern{patterns[i], patterns[j], patterns[k]})
+
+				// The legs must be about equal and meet at a right angle
+				legA, legB, hyp := dist(t[0], t[1]), dist(t[1], t[2]), dist(t[2], t[0])
+				size := (t[0].size + t[1].size + t[2].size) / 3
+				modules := (legA + legB) / (2 * size)
+				if modules < 9 || modules > 180 {
+					continue
+				}
+				if math.Abs(legA-legB)/min(legA, legB) > 0.2 {
+					continue
+				}
+				expected := math.Hypot(legA, legB)
+				if math.Abs(hyp-expected)/min(hyp, expected) > 0.2 {
+					continue
+				}
+				triples = append(triples, t)
+			}
+		}
+	}
+
+	perimeter := func(t finderTriple) float64 {
+		return dist(t[0], t[1]) + dist(t[1], t[2]) + dist(t[2], t[0])
+	}
+	sort.SliceStable(triples, func(i, j int) bool {
+		return perimeter(triples[i]) < perimeter(triples[j])
+	})
+	return triples
+}
+
+// candidateDimensions returns the symbol widths, most likely first, that
+// fit the distances between the finder patterns
+func candidateDimensions(t finderTriple) []int {
+	size := (t[0].size + t[1].size + t[2].size) / 3
+	across := (math.Round(dist(t[1], t[2])/size) + math.Round(dist(t[1], t[0])/size)) / 2
+	raw := across + 7
+
+	// Valid widths are 4n+1; try the nearest first, then its neighbours
+	nearest := int(math.Round((raw-1)/4))*4 + 1
+	var dims []int
+	for _, d := range []int{nearest, nearest - 4, nearest + 4} {
+		if d >= symbolSize(1) && d <= symbolSize(40) {
+			dims = append(dims, d)
+		}
+	}
+	return dims
+}
+
+// findAlignment looks for the bottom-right alignment pattern within
+// radius pixels of (ex, ey): a dark module ringed by light, each about
+// size pixels wide. It returns false when none is found.
+func findAlignment(img *bitImage, ex, ey, size, radius float64) (float64, float64, bool) {
+	left := max(0, int(ex-radius))
+	right := min(img.w-1, int(ex+radius))
+	top := max(0, int(ey-radius))
+	bottom := min(img.h-1, int(ey+radius))
+	if float64(right-left) < 3*size || float64(bottom-top) < 3*size {
+		return 0, 0, false
+	}
+
+	ratio := func(runs [3]int) bool {
+		for _, r := range runs {
+			if math.Abs(size-float64(r)) >= size/2 {
+				return false
+			}
+		}
+		return true
+	}
+
+	vertical := func(x, y, maxRun, total int) float64 {
+		var runs [3]int
+		cy := y
+		for cy >= 0 && img.at(x, cy) && runs[1] <= maxRun {
+			runs[1]++
+			cy--
+		}
+		if cy < 0 || runs[1] > maxRun {
+			return math.NaN()
+		}
+		for cy >= 0 && !img.at(x, cy) && runs[0] <= maxRun {
+			runs[0]++
+			cy--
+		}
+		if runs[0] > maxRun {
+			return math.NaN()
+		}
+		cy = y + 1
+		for cy < img.h && img.at(x, cy) && runs[1] <= maxRun {
+			runs[1]++
+			cy++
+		}
+		if cy == img.h || runs[1] > maxRun {
+			return math.NaN()
+		}
+		for cy < img.h && !img.at(x, cy) && runs[2] <= maxRun {
+			runs[2]++
+			cy++
+		}
+		if runs[2] > maxRun {
+			return math.NaN()
+		}
+		sum := runs[0] + runs[1] + runs[2]
+		if 5*abs(sum-total) >= 2*total || !ratio(runs) {
+			return math.NaN()
+		}
+		return float64(cy-runs[2]) - float64(runs[1])/2
+	}
+
+	type candidate struct{ x, y float64 }
+	var candidates []candidate
+	middle := (top + bottom) / 2
+	for i := 0; i <= bottom-top; i++ {
+		// Scan outwards from the expected row
+		y := middle + (i+1)/2
+		if i%2 == 1 {
+			y = middle - (i+1)/2
+		}
+		if y < top || y > bottom {
+			continue
+		}
+
+		var runs [3]int
+		state := 0
+		try := func(end int) (float64, float64, bool) {
+			if !ratio(runs) {
+				return 0, 0, false
+			}
+			total := runs[0] + runs[1] + runs[2]
+			cx := float64(end-runs[2]) - float64(runs[1])/2
+			cy := vertical(int(cx), y, 2*runs[1], total)
+			if math.IsNaN(cy) {
+				return 0, 0, false
+			}
+			// A second sighting on another row confirms the pattern
+			for _, c := range candidates {
+				if math.Abs(cx-c.x) <= size && math.Abs(cy-c.y) <= size {
+					return (c.x + cx) / 2, (c.y + cy) / 2, true
+				}
+			}
+			candidates = append(candidates, candidate{cx, cy})
+			return 0, 0, false
+		}
+
+		// Skip leading light pixels; a run cut by the region edge is useless
+		x := left
+		for x <= right && !img.at(x, y) {
+			x++
+		}
+		for ; x <= right; x++ {
+			if !img.at(x, y) {
+				if state == 1 {
+					state++
+				}
+				runs[state]++
+				continue
+			}
+			switch state {
+			case 1:
+				runs[1]++
+			case 2:
+				if ax, ay, ok := try(x); ok {
+					return ax, ay, true
+				}
+				runs = [3]int{runs[2], 1, 0}
+				state = 1
+			default:
+				state++
+				runs[state]++
+			}
+		}
+		if state == 2 {
+			if ax, ay, ok := try(right + 1); ok {
+				return ax, ay, true
+			}
+		}
+	}
+
+	if len(candidates) > 0 {
+		return candidates[0].x, candidates[0].y, true
+	}
+	return 0, 0, false
+}
+
+// homography maps module coordinates to pixel coordinates
+type homography [8]float64
+
+func (h homography) apply(u, v float64) (float64, float64) {
+	w := h[6]*u + h[7]*v + 1
+	return (h[0]*u + h[1]*v + h[2]) / w, (h[3]*u + h[4]*v + h[5]) / w
+}
+
+// solveHomography finds the projective transform taking each src point to
+// the matching dst point
+func solveHomography(src, dst [4][2]float64) (homography, bool) {
+	var m [8][9]float64
+	for i := 0; i < 4; i++ {
+		u, v := src[i][0], src[i][1]
+		x, y := dst[i][0], dst[i][1]
+		m[2*i] = [9]float64{u, v, 1, 0, 0, 0, -u * x, -v * x, x}
+		m[2*i+1] = [9]float64{0, 0, 0, u, v, 1, -u * y, -v * y, y}
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return homography{}, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			f := m[row][col] / m[col][col]
+			for k := col; k < 9; k++ {
+				m[row][k] -= f * m[col][k]
+			}
+		}
+	}
+
+	var h homography
+	for i := range h {
+		h[i] = m[i][8] / m[i][i]
+	}
+	return h, true
+}
+
+// locate returns the transform from module to pixel coordinates for a
+// symbol of width dim whose finder patterns are t
+func locate(img *bitImage, t finderTriple, dim int) (homography, bool) {
+	bl, tl, tr := t[0], t[1], t[2]
+	d := float64(dim)
+
+	// Without an alignment pattern, assume a parallelogram
+	brX, brY := tr.x-tl.x+bl.x, tr.y-tl.y+bl.y
+	corner := d - 3.5
+
+	if dim > symbolSize(1) {
+		size := (bl.size + tl.size + tr.size) / 3
+		// The alignment pattern sits 3 modules in from the finder centres
+		correction := 1 - 3/(d-7)
+		ex := tl.x + correction*(brX-tl.x)
+		ey := tl.y + correction*(brY-tl.y)
+		for radius := 4.0; radius <= 16; radius *= 2 {
+			if ax, ay, ok := findAlignment(img, ex, ey, size, radius*size); ok {
+				brX, brY, corner = ax, ay, d-6.5
+				break
+			}
+		}
+	}
+
+	return solveHomography(
+		[4][2]float64{{3.5, 3.5}, {d - 3.5, 3.5}, {corner, corner}, {3.5, d - 3.5}},
+		[4][2]float64{{tl.x, tl.y}, {tr.x, tr.y}, {brX, brY}, {bl.x, bl.y}},
+	)
+}
+
+// sampleGrid reads the module at the centre of every cell. Points that
+// fall just outside the image are clamped; anything further out means the
+// transform is wrong.
+func sampleGrid(img *bitImage, h homography, dim int) ([][]bool, bool) {
+	m := make([][]bool, dim)
+	for y := range m {
+		m[y] = make([]bool, dim)
+		for x := range m[y] {
+			px, py := h.apply(float64(x)+0.5, float64(y)+0.5)
+			ix, iy := int(math.Floor(px)), int(math.Floor(py))
+			if ix < -1 || iy < -1 || ix > img.w || iy > img.h {
+				return nil, false
+			}
+			ix = min(max(ix, 0), img.w-1)
+			iy = min(max(iy, 0), img.h-1)
+			m[y][x] = img.at(ix, iy)
+		}
+	}
+	return m, true
+}
@@ -0,0 +1,393 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errNoData is returned for empty content, which has no valid encoding
+var errNoData = errors.New("no data to encode")
+
+// segment is a run of content encoded in one mode. data holds the digits
+// or alphanumeric characters for those modes, the raw bytes for byte mode,
+// the two byte Shift-JIS codes for kanji mode and the designator for an
+// ECI header.
+type segment struct {
+	mode int
+	data []byte
+}
+
+// count returns the value of the segment's character count field
+func (s segment) count() int {
+	if s.mode == modeKanji {
+		return len(s.data) / 2
+	}
+	return len(s.data)
+}
+
+// dataBits returns the length of the segment's data, without its header
+func (s segment) dataBits() int {
+	n := len(s.data)
+	switch s.mode {
+	case modeNumeric:
+		return 10*(n/3) + [3]int{0, 4, 7}[n%3]
+	case modeAlphanumeric:
+		return 11*(n/2) + 6*(n%2)
+	case modeKanji:
+		return 13 * (n / 2)
+	default:
+		return 8 * n
+	}
+}
+
+// bits returns the encoded length of the segment in a symbol of the given
+// version, or false when its character count does not fit the count field
+func (s segment) bits(version int) (int, bool) {
+	if s.mode == modeECI {
+		return 4 + s.dataBits(), true
+	}
+	countBits := charCountBits(s.mode, version)
+	if countBits == 0 || s.count() >= 1<<countBits {
+		return 0, false
+	}
+	return modeBits(version) + countBits + s.dataBits(), true
+}
+
+// write appends the mode indicator, character count and data
+func (s segment) write(w *bitWriter, version int) {
+	if version < 0 {
+		w.write(microModeIndicator(s.mode), modeBits(version))
+	} else {
+		w.write(s.mode, 4)
+	}
+	if s.mode != modeECI {
+		w.write(s.count(), charCountBits(s.mode, version))
+	}
+
+	switch s.mode {
+	case modeNumeric:
+		for i := 0; i < len(s.data); i += 3 {
+			group := s.data[i:min(i+3, len(s.data))]
+			v := 0
+			for _, c := range group {
+				v = v*10 + int(c-'0')
+			}
+			w.write(v, [4]int{0, 4, 7, 10}[len(group)])
+		}
+	case modeAlphanumeric:
+		for i := 0; i+1 < len(s.data); i += 2 {
+			w.write(alphanumericValue(s.data[i])*45+alphanumericValue(s.data[i+1]), 11)
+		}
+		if len(s.data)%2 == 1 {
+			w.write(alphanumericValue(s.data[len(s.data)-1]), 6)
+		}
+	case modeKanji:
+		for i := 0; i+1 < len(s.data); i += 2 {
+			w.write(packKanji(uint16(s.data[i])<<8|uint16(s.data[i+1])), 13)
+		}
+	default:
+		for _, c := range s.data {
+			w.write(int(c), 8)
+		}
+	}
+}
+
+func alphanumericValue(c byte) int {
+	return strings.IndexByte(alphanumericCharset, c)
+}
+
+func isNumeric(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlphanumeric(c byte) bool {
+	return alphanumericValue(c) >= 0
+}
+
+// segmentModes lists the modes segmentContent chooses between by default
+var segmentModes = []int{modeNumeric, modeAlphanumeric, modeByte, modeKanji}
+
+// unitCost returns the width in bytes and the encoded length in sixths of a
+// bit of the character starting at p.data[i] in mode, or false when mode
+// cannot hold it. Sixths make the 10 bits per 3 digits and 11 bits per 2
+// alphanumeric characters whole numbers.
+func unitCost(mode int, p payload, i int) (int, int, bool) {
+	switch mode {
+	case modeNumeric:
+		return 1, 20, isNumeric(p.data[i])
+	case modeAlphanumeric:
+		return 1, 33, isAlphanumeric(p.data[i])
+	case modeKanji:
+		return p.widths[i], 78, p.kanji[i] != 0
+	default:
+		return p.widths[i], 48 * p.widths[i], true
+	}
+}
+
+// segmentContent splits the payload data into the segments with the
+// shortest encoding in a symbol of the given version, never splitting a
+// character, or returns nil when the version has no mode for one. It
+// finds the cheapest way to reach each character boundary with each mode
+// open, paying a segment header whenever the mode changes, then walks the
+// cheapest path back.
+func segmentContent(p payload, version int) []segment {
+	n := len(p.data)
+	if n == 0 {
+		return nil
+	}
+
+	// cost[i][k] is the cheapest encoding of data[:i] whose last segment is
+	// in p.modes[k] and still open, back[i][k] the state it came from
+	type state struct{ pos, mode int }
+	cost := make([][]int, n+1)
+	back := make([][]state, n+1)
+	for i := range cost {
+		cost[i] = make([]int, len(p.modes))
+		back[i] = make([]state, len(p.modes))
+		for k := range cost[i] {
+			cost[i][k] = -1
+		}
+	}
+	closed := func(c int) int { return (c + 5) / 6 * 6 }
+
+	for i := 0; i < n; i++ {
+		if p.widths[i] == 0 {
+			continue
+		}
+		for k, mode := range p.modes {
+			width, unit, ok := unitCost(mode, p, i)
+			if !ok || charCountBits(mode, version) == 0 {
+				continue
+			}
+			header := 6 * (modeBits(version) + charCountBits(mode, version))
+			update := func(c, prev int) {
+				if next := &cost[i+width][k]; *next < 0 || c < *next {
+					*next, back[i+width][k] = c, state{i, prev}
+				}
+			}
+			if i == 0 {
+				update(header+unit, -1)
+				continue
+			}
+			for prev := range p.modes {
+				switch c := cost[i][prev]; {
+				case c < 0:
+				case prev == k:
+					update(c+unit, prev)
+				default:
+					update(closed(c)+header+unit, prev)
+				}
+			}
+		}
+	}
+
+	best := -1
+	for k, c := range cost[n] {
+		if c >= 0 && (best < 0 || closed(c) < closed(cost[n][best])) {
+			best = k
+		}
+	}
+	if best < 0 {
+		// Only Micro QR symbols lack a mode for some characters
+		return nil
+	}
+
+	// Walk back, growing the current segment until the mode changes
+	var segs []segment
+	end := n
+	for i, k := n, best; i > 0; {
+		from := back[i][k]
+		if from.mode != k {
+			segs = append(segs, p.segment(p.modes[k], from.pos, end, version)...)
+			end = from.pos
+		}
+		i, k = from.pos, from.mode
+	}
+	for i, j := 0, len(segs)-1; i < j; i, j = i+1, j-1 {
+		segs[i], segs[j] = segs[j], segs[i]
+	}
+	return segs
+}
+
+// segment returns data[start:end] as segments of mode whose character
+// counts fit the count fields of the version, in reverse order for
+// segmentContent. Kanji segments hold the Shift-JIS codes of the
+// characters instead of the payload bytes.
+func (p payload) segment(mode, start, end, version int) []segment {
+	data, widths := p.data[start:end], p.widths[start:end]
+	limit := 1<<charCountBits(mode, version) - 1
+	if mode == modeKanji {
+		var codes []byte
+		for _, code := range p.kanji[start:end] {
+			if code != 0 {
+				codes = append(codes, byte(code>>8), byte(code))
+			}
+		}
+		data, widths, limit = codes, nil, 2*limit
+	}
+
+	var segs []segment
+	for len(data) > limit {
+		cut := len(data) - limit
+		for widths != nil && widths[cut] == 0 {
+			cut++
+		}
+		segs = append(segs, segment{mode: mode, data: data[cut:]})
+		data = data[:cut]
+		if widths != nil {
+			widths = widths[:cut]
+		}
+	}
+	return append(segs, segment{mode: mode, data: data})
+}
+
+// segmentsBits returns the encoded length of segments in a symbol of the
+// given version, or false when a count field overflows
+func segmentsBits(segs []segment, version int) (int, bool) {
+	total := 0
+	for _, s := range segs {
+		n, ok := s.bits(version)
+		if !ok {
+			return 0, false
+		}
+		total += n
+	}
+	return total, true
+}
+
+// fitVersion returns the smallest version of at least minVersion whose
+// data capacity at level holds p, with the segments encoding it. The
+// segmentation is redone where the count fields widen, at versions 10
+// and 27.
+func fitVersion(p payload, level ErrorCorrectionLevel, minVersion int) (int, []segment, error) {
+	var segs []segment
+	for version := max(minVersion, 1); version <= 40; version++ {
+		if segs == nil || version == 10 || version == 27 {
+			segs = p.segments(version)
+		}
+		n, ok := segmentsBits(segs, version)
+		if ok && n <= 8*versions[version-1].ec[level].dataCodewords() {
+			return version, segs, nil
+		}
+	}
+	n, _ := segmentsBits(segs, 40)
+	return 0, nil, fmt.Errorf("content too long: needs %d bits, version 40-%s holds %d", n, level, 8*versions[39].ec[level].dataCodewords())
+}
+
+// byteModeVersion returns the smallest version holding p as a single
+// byte segment, as an encoder without segmentation would, or 0 when no
+// version does
+func byteModeVersion(p payload, level ErrorCorrectionLevel) int {
+	segs := []segment{{mode: modeByte, data: p.data}}
+	if p.eci >= 0 {
+		segs = append([]segment{eciSegment(p.eci)}, segs...)
+	}
+	for version := 1; version <= 40; version++ {
+		n, ok := segmentsBits(segs, version)
+		if ok && n <= 8*versions[version-1].ec[level].dataCodewords() {
+			return version
+		}
+	}
+	return 0
+}
+
+// dataCodewords encodes segs and pads them to the data capacity of the
+// version at level
+func dataCodewords(segs []segment, version int, level ErrorCorrectionLevel) []byte {
+	capacity := 8 * versions[version-1].ec[level].dataCodewords()
+	w := &bitWriter{}
+	for _, s := range segs {
+		s.write(w, version)
+	}
+
+	// Terminator of up to four zero bits, then zeros to the byte boundary
+	w.write(0, min(4, capacity-w.n))
+	w.write(0, (8-w.n%8)%8)
+	for i := 0; w.n < capacity; i++ {
+		w.write([2]int{0xec, 0x11}[i%2], 8)
+	}
+	return w.data
+}
+
+// interleave splits data codewords into blocks, appends the error
+// correction codewords of each block and interleaves them in the order
+// they are placed in the symbol
+func interleave(data []byte, version int, level ErrorCorrectionLevel) []byte {
+	ec := versions[version-1].ec[level]
+
+	var blocks, ecc [][]byte
+	pos := 0
+	for _, g := range ec.groups {
+		for i := 0; i < g.count; i++ {
+			block := data[pos : pos+g.data]
+			pos += g.data
+			blocks = append(blocks, block)
+			ecc = append(ecc, rsEncode(block, ec.ecPerBlock))
+		}
+	}
+
+	result := make([]byte, 0, totalCodewords(version))
+	longest := len(blocks[len(blocks)-1])
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < ec.ecPerBlock; i++ {
+		for _, block := range ecc {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// encodeSymbol encodes p in a symbol of the given version and mask.
+// A version of 0 picks the smallest that fits and AutoMask picks the mask
+// with the lowest penalty. It returns the symbol, without a quiet zone,
+// and the version and mask used.
+func encodeSymbol(p payload, level ErrorCorrectionLevel, version, mask int) ([][]bool, int, int, error) {
+	if len(p.data) == 0 {
+		return nil, 0, 0, errNoData
+	}
+	fit, segs, err := fitVersion(p, level, version)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if version != 0 && fit != version {
+		return nil, 0, 0, fmt.Errorf("content needs version %d at level %s, it does not fit in version %d", fit, level, version)
+	}
+
+	codewords := interleave(dataCodewords(segs, fit, level), fit, level)
+	symbol, mask := buildSymbol(codewords, fit, level, mask)
+	return symbol, fit, mask, nil
+}
+
+// modeBits returns the width of the mode indicator in a symbol of the
+// given version: 4 bits, or 0 to 3 for Micro QR versions -1 to -4
+func modeBits(version int) int {
+	if version < 0 {
+		return -version - 1
+	}
+	return 4
+}
+
+// bitWriter appends big-endian bit fields to a byte slice
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) write(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if v>>i&1 == 1 {
+			w.data[w.n/8] |= 0x80 >> (w.n % 8)
+		}
+		w.n++
+	}
+}
@@ -0,0 +1,284 @@
+package qr
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDataCodewords(t *testing.T) {
+	// HELLO WORLD at 1-M, the worked example of ISO/IEC 18004 annex I
+	segs := segmentContent(textPayload(t, "HELLO WORLD"), 1)
+	data := dataCodewords(segs, 1, LevelM)
+	wantData := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	if !bytes.Equal(data, wantData) {
+		t.Errorf("dataCodewords() = %v, want %v", data, wantData)
+	}
+
+	ec := rsEncode(data, 10)
+	wantEC := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if !bytes.Equal(ec, wantEC) {
+		t.Errorf("rsEncode() = %v, want %v", ec, wantEC)
+	}
+
+	// The decoder must accept what the encoder produces
+	block := append(append([]byte{}, data...), ec...)
+	block[3] ^= 0xff
+	block[20] ^= 0x0f
+	if n, err := rsCorrect(block, 10); err != nil || n != 2 {
+		t.Errorf("rsCorrect() = %d, %v, want 2 corrections", n, err)
+	}
+}
+
+func TestSegmentContent(t *testing.T) {
+	tests := []struct {
+		content string
+		modes   []int
+	}{
+		{"0123456789", []int{modeNumeric}},
+		{"HELLO WORLD $%*+-./:", []int{modeAlphanumeric}},
+		{"Hello World", []int{modeByte}},
+		{"Grüße", []int{modeByte}},
+		// Short runs are cheaper left in the surrounding segment
+		{"a1b", []int{modeByte}},
+		{"A1B2C3", []int{modeAlphanumeric}},
+		{"abc0123456789012", []int{modeByte, modeNumeric}},
+		{"TEL:+4915112345678", []int{modeAlphanumeric, modeNumeric}},
+		{"otpauth://totp/x?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&digits=6", []int{modeByte, modeAlphanumeric, modeByte}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			segs := segmentContent(textPayload(t, tt.content), 1)
+			var modes []int
+			var joined []byte
+			for _, s := range segs {
+				modes = append(modes, s.mode)
+				joined = append(joined, s.data...)
+			}
+			if !slices.Equal(modes, tt.modes) {
+				t.Errorf("segmentContent(%q) modes = %v, want %v", tt.content, modes, tt.modes)
+			}
+			if string(joined) != tt.content {
+				t.Errorf("segmentContent(%q) data = %q", tt.content, joined)
+			}
+
+			// Never longer than the content in a single mode
+			single, _ := segmentsBits([]segment{{mode: modeByte, data: []byte(tt.content)}}, 1)
+			if n, _ := segmentsBits(segs, 1); n > single {
+				t.Errorf("segmentContent(%q) needs %d bits, byte mode %d", tt.content, n, single)
+			}
+		})
+	}
+}
+
+func TestSegmentContentCountLimit(t *testing.T) {
+	// Byte mode counts hold 255 below version 10 and 65535 from it
+	p := textPayload(t, strings.Repeat("a", 300))
+	if segs := segmentContent(p, 1); len(segs) != 2 || len(segs[0].data) != 45 || len(segs[1].data) != 255 {
+		t.Errorf("segmentContent() at version 1 = %d segments, want 45 and 255 bytes", len(segs))
+	}
+	if segs := segmentContent(p, 10); len(segs) != 1 {
+		t.Errorf("segmentContent() at version 10 = %d segments, want 1", len(segs))
+	}
+}
+
+func TestSegmentationSavesVersions(t *testing.T) {
+	tests := []struct {
+		content     string
+		version     int
+		byteVersion int
+	}{
+		{"otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Example", 6, 7},
+		{"WIFI:T:WPA;S:OFFICE-GUEST-5G;P:8467239512846723;;", 3, 4},
+		{"https://example.com", 2, 2},
+	}
+
+	for _, tt := range tests {
+		version, _, err := fitVersion(textPayload(t, tt.content), LevelM, 0)
+		if err != nil {
+			t.Fatalf("fitVersion(%q) error: %v", tt.content, err)
+		}
+		byteVersion := byteModeVersion(textPayload(t, tt.content), LevelM)
+		if version != tt.version || byteVersion != tt.byteVersion {
+			t.Errorf("%q fits version %d, byte mode %d, want %d and %d", tt.content, version, byteVersion, tt.version, tt.byteVersion)
+		}
+	}
+
+	digits := textPayload(t, strings.Repeat("0123456789", 700))
+	if _, _, err := fitVersion(digits, LevelL, 0); err != nil {
+		t.Errorf("fitVersion() of 7000 digits error: %v", err)
+	}
+	if v := byteModeVersion(digits, LevelL); v != 0 {
+		t.Errorf("byteModeVersion() of 7000 digits = %d, want 0", v)
+	}
+}
+
+func TestSegmentBits(t *testing.T) {
+	tests := []struct {
+		seg     segment
+		version int
+		bits    int
+		ok      bool
+	}{
+		{segment{modeNumeric, []byte("01234567")}, 1, 4 + 10 + 27, true},
+		{segment{modeAlphanumeric, []byte("AC-42")}, 1, 4 + 9 + 28, true},
+		{segment{modeByte, []byte("abc")}, 10, 4 + 16 + 24, true},
+		{segment{modeByte, bytes.Repeat([]byte("a"), 256)}, 9, 0, false},
+	}
+
+	for _, tt := range tests {
+		bits, ok := tt.seg.bits(tt.version)
+		if bits != tt.bits || ok != tt.ok {
+			t.Errorf("bits(%d) of %d-byte mode %d segment = %d, %v, want %d, %v", tt.version, len(tt.seg.data), tt.seg.mode, bits, ok, tt.bits, tt.ok)
+		}
+	}
+}
+
+// textPayload returns content as a payload in the automatic character set
+func textPayload(t *testing.T, content string) payload {
+	t.Helper()
+	p, err := newPayload(content, CharsetAuto, ModeAuto)
+	if err != nil {
+		t.Fatalf("newPayload(%q) error: %v", content, err)
+	}
+	return p
+}
+
+// decodeSymbol reads a symbol back with the decoder's bitstream functions
+func decodeSymbol(t *testing.T, symbol [][]bool) (string, int, ErrorCorrectionLevel, int) {
+	t.Helper()
+	level, mask, err := readFormat(symbol)
+	if err != nil {
+		t.Fatalf("readFormat() error: %v", err)
+	}
+	version, err := readVersion(symbol)
+	if err != nil {
+		t.Fatalf("readVersion() error: %v", err)
+	}
+	data, err := correctCodewords(readCodewords(symbol, version, mask), version, level)
+	if err != nil {
+		t.Fatalf("correctCodewords() error: %v", err)
+	}
+	content, err := parseSegments(data, version)
+	if err != nil {
+		t.Fatalf("parseSegments() error: %v", err)
+	}
+	return content, version, level, mask
+}
+
+func TestEncodeSymbolRoundTrip(t *testing.T) {
+	tests := []struct {
+		content string
+		level   ErrorCorrectionLevel
+		version int
+		mask    int
+	}{
+		{"0123456789", LevelL, 0, AutoMask},
+		{"HELLO WORLD", LevelM, 0, 3},
+		{"https://example.com/path?q=1", LevelQ, 0, AutoMask},
+		{"WIFI:T:WPA;S:Home;P:password123;;", LevelH, 7, 5},
+		{"Grüße, 世界", LevelM, 0, 0},
+		{strings.Repeat("0123456789", 300), LevelL, 0, AutoMask},
+		{strings.Repeat("mkQR ", 200), LevelH, 0, 7},
+		{strings.Repeat("x", 2953), LevelL, 40, AutoMask},
+		{strings.Repeat("0123456789", 708) + "0", LevelL, 0, AutoMask},
+		{"MECARD:N:Doe,John;TEL:+14155552671;EMAIL:john@example.com;;", LevelQ, 0, AutoMask},
+		{"abc0123456789012ÀÉ HELLO WORLD 42", LevelM, 12, 1},
+	}
+
+	for _, tt := range tests {
+		name := tt.content
+		if len(name) > 20 {
+			name = name[:20]
+		}
+		t.Run(name, func(t *testing.T) {
+			symbol, version, mask, err := encodeSymbol(textPayload(t, tt.content), tt.level, tt.version, tt.mask)
+			if err != nil {
+				t.Fatalf("encodeSymbol() error: %v", err)
+			}
+			if len(symbol) != symbolSize(version) {
+				t.Errorf("symbol is %d modules wide, want %d", len(symbol), symbolSize(version))
+			}
+			if tt.version != 0 && version != tt.version {
+				t.Errorf("version = %d, want %d", version, tt.version)
+			}
+			if tt.mask != AutoMask && mask != tt.mask {
+				t.Errorf("mask = %d, want %d", mask, tt.mask)
+			}
+
+			content, gotVersion, gotLevel, gotMask := decodeSymbol(t, symbol)
+			if content != tt.content {
+				t.Errorf("decoded %q, want %q", content, tt.content)
+			}
+			if gotVersion != version || gotLevel != tt.level || gotMask != mask {
+				t.Errorf("decoded %d-%s mask %d, want %d-%s mask %d", gotVersion, gotLevel, gotMask, version, tt.level, mask)
+			}
+		})
+	}
+}
+
+func TestEncodeSymbolErrors(t *testing.T) {
+	if _, err := newPayload("", CharsetAuto, ModeAuto); err != errNoData {
+		t.Errorf("newPayload() with empty content error = %v, want %v", err, errNoData)
+	}
+	if _, _, _, err := encodeSymbol(textPayload(t, strings.Repeat("x", 100)), LevelM, 2, AutoMask); err == nil {
+		t.Error("encodeSymbol() with content too long for version 2 should fail")
+	}
+	if _, _, _, err := encodeSymbol(textPayload(t, strings.Repeat("x", 2954)), LevelL, 0, AutoMask); err == nil {
+		t.Error("encodeSymbol() beyond version 40 capacity should fail")
+	}
+}
+
+func TestAutoMaskLowestPenalty(t *testing.T) {
+	content := "https://github.com/Lynthar/mkQR"
+	_, version, auto, err := encodeSymbol(textPayload(t, content), LevelM, 0, AutoMask)
+	if err != nil {
+		t.Fatalf("encodeSymbol() error: %v", err)
+	}
+
+	// The first mask reaching the lowest penalty wins
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		symbol, _, _, err := encodeSymbol(textPayload(t, content), LevelM, version, mask)
+		if err != nil {
+			t.Fatalf("encodeSymbol() error: %v", err)
+		}
+		if p := penalty(symbol); bestPenalty < 0 || p < bestPenalty {
+			bestMask, bestPenalty = mask, p
+		}
+	}
+	if auto != bestMask {
+		t.Errorf("auto mask = %d, want %d with penalty %d", auto, bestMask, bestPenalty)
+	}
+}
+
+func TestGeneratorVersionMask(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Version = 6
+	opts.Mask = 4
+	code, err := NewGenerator(opts).Generate("Hello World")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if code.Version != 6 || code.Mask != 4 || code.Level != LevelM {
+		t.Errorf("Generate() = %d-%s mask %d, want 6-M mask 4", code.Version, code.Level, code.Mask)
+	}
+	if err := Verify(code, "Hello World", 256); err != nil {
+		t.Errorf("Verify() error: %v", err)
+	}
+
+	for _, bad := range []func(*Options){
+		func(o *Options) { o.Version = 41 },
+		func(o *Options) { o.Version = -1 },
+		func(o *Options) { o.Mask = 8 },
+		func(o *Options) { o.Mask = -2 },
+	} {
+		opts := DefaultOptions()
+		bad(&opts)
+		if _, err := NewGenerator(opts).Generate("Hello World"); err == nil {
+			t.Errorf("Generate() with version %d mask %d should fail", opts.Version, opts.Mask)
+		}
+	}
+}
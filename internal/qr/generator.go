@@ -2,9 +2,9 @@ package qr
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-
-	"github.com/skip2/go-qrcode"
+	"strings"
 )
 
 // ErrorCorrectionLevel represents QR code error correction level
@@ -33,18 +33,51 @@ func ParseLevel(s string) (ErrorCorrectionLevel, error) {
 	}
 }
 
-func (l ErrorCorrectionLevel) toQRCode() qrcode.RecoveryLevel {
+// String returns the level letter (L, M, Q or H)
+func (l ErrorCorrectionLevel) String() string {
 	switch l {
 	case LevelL:
-		return qrcode.Low
+		return "L"
 	case LevelM:
-		return qrcode.Medium
+		return "M"
 	case LevelQ:
-		return qrcode.High
+		return "Q"
 	case LevelH:
-		return qrcode.Highest
+		return "H"
+	default:
+		return fmt.Sprintf("ErrorCorrectionLevel(%d)", int(l))
+	}
+}
+
+// Mode restricts the segment modes content is encoded in
+type Mode int
+
+const (
+	ModeAuto  Mode = iota // Shortest mix of numeric, alphanumeric, byte and kanji segments
+	ModeKanji             // Kanji segments only, for Japanese text
+)
+
+// ParseMode parses a segment mode name as accepted by --mode
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return ModeAuto, nil
+	case "kanji":
+		return ModeKanji, nil
+	default:
+		return ModeAuto, fmt.Errorf("invalid mode: %s (use auto or kanji)", s)
+	}
+}
+
+// String returns the mode name
+func (m Mode) String() string {
+	switch m {
+	case ModeAuto:
+		return "auto"
+	case ModeKanji:
+		return "kanji"
 	default:
-		return qrcode.Medium
+		return fmt.Sprintf("Mode(%d)", int(m))
 	}
 }
 
@@ -54,8 +87,23 @@ type Options struct {
 	Size            int
 	ForegroundColor color.Color
 	BackgroundColor color.Color
+	Logo            image.Image // Optional image drawn over the centre of the code
+	LogoScale       float64     // Logo width as a fraction of the symbol width
+	ModuleStyle     ModuleStyle // Shape of data modules (PNG and SVG)
+	EyeStyle        EyeStyle    // Shape of the finder patterns (PNG and SVG)
+	Margin          int         // Quiet zone width in modules, for every output
+	ModuleSize      int         // Exact pixels per module; overrides Size when positive
+	Version         int         // Symbol version 1-40, or 0 for the smallest that fits
+	Mask            int         // Mask pattern 0-7, or AutoMask for the lowest penalty
+	ECI             Charset     // Character set of byte segments, announced by an ECI header
+	Mode            Mode        // Segment modes to encode in, ModeAuto for the shortest mix
+	Micro           bool        // Micro QR symbol; Version 1-4 and Mask 0-3 then mean M1-M4
 }
 
+// DefaultMargin is the quiet zone width required by the QR specification,
+// in modules
+const DefaultMargin = 4
+
 // DefaultOptions returns default QR generation options
 func DefaultOptions() Options {
 	return Options{
@@ -63,9 +111,26 @@ func DefaultOptions() Options {
 		Size:            256,
 		ForegroundColor: color.Black,
 		BackgroundColor: color.White,
+		LogoScale:       DefaultLogoScale,
+		Margin:          DefaultMargin,
+		Mask:            AutoMask,
 	}
 }
 
+// Code is a generated QR code together with the options used to render it
+type Code struct {
+	Version         int // Symbol version, 1 to 40, or 1 to 4 for Micro QR M1-M4
+	Level           ErrorCorrectionLevel
+	Mask            int  // Mask pattern, 0 to 7, or 0 to 3 for Micro QR
+	Micro           bool // Micro QR symbol
+	ByteModeVersion int  // Version content needs as plain byte mode, 0 if none holds it or for Micro QR
+	ForegroundColor color.Color
+	BackgroundColor color.Color
+
+	symbol [][]bool // Modules without the quiet zone
+	opts   Options
+}
+
 // Generator creates QR codes
 type Generator struct {
 	opts Options
@@ -77,32 +142,128 @@ func NewGenerator(opts Options) *Generator {
 }
 
 // Generate creates a QR code from content
-func (g *Generator) Generate(content string) (*qrcode.QRCode, error) {
-	qr, err := qrcode.New(content, g.opts.Level.toQRCode())
+func (g *Generator) Generate(content string) (*Code, error) {
+	opts := g.opts
+
+	if opts.Margin < 0 {
+		return nil, fmt.Errorf("margin cannot be negative, got %d", opts.Margin)
+	}
+	if opts.ModuleSize < 0 {
+		return nil, fmt.Errorf("module size cannot be negative, got %d", opts.ModuleSize)
+	}
+
+	if opts.Micro && opts.Logo != nil {
+		return nil, fmt.Errorf("a logo does not fit on a Micro QR symbol")
+	}
+	if opts.Micro && opts.ECI != CharsetAuto {
+		return nil, fmt.Errorf("Micro QR symbols cannot carry an ECI header, use the auto character set")
+	}
+
+	// A logo hides modules, so make sure enough of them can be recovered
+	if opts.Logo != nil {
+		if opts.LogoScale == 0 {
+			opts.LogoScale = DefaultLogoScale
+		}
+		level, err := LogoLevel(opts.Level, opts.LogoScale)
+		if err != nil {
+			return nil, err
+		}
+		opts.Level = level
+	}
+
+	maxVersion, maxMask := 40, 7
+	if opts.Micro {
+		maxVersion, maxMask = 4, 3
+	}
+	if opts.Version < 0 || opts.Version > maxVersion {
+		return nil, fmt.Errorf("version must be between 1 and %d, got %d", maxVersion, opts.Version)
+	}
+	if opts.Mask < AutoMask || opts.Mask > maxMask {
+		return nil, fmt.Errorf("mask must be between 0 and %d, got %d", maxMask, opts.Mask)
+	}
+
+	p, err := newPayload(content, opts.ECI, opts.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	var symbol [][]bool
+	var version, mask, byteVersion int
+	if opts.Micro {
+		symbol, version, mask, err = encodeMicro(p, opts.Level, opts.Version, opts.Mask)
+	} else {
+		version = opts.Version
+		if opts.Logo != nil {
+			// The logo box may hide more than its area suggests, such as
+			// the alignment pattern of small versions
+			opts.Level, version, err = logoFit(p, opts.Level, version, opts.LogoScale)
+		}
+		if err == nil {
+			symbol, version, mask, err = encodeSymbol(p, opts.Level, version, opts.Mask)
+		}
+		byteVersion = byteModeVersion(p, opts.Level)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate QR code: %w", err)
 	}
 
+	qr := &Code{
+		Version:         version,
+		Level:           opts.Level,
+		Mask:            mask,
+		Micro:           opts.Micro,
+		ByteModeVersion: byteVersion,
+		ForegroundColor: opts.ForegroundColor,
+		BackgroundColor: opts.BackgroundColor,
+		symbol:          symbol,
+		opts:            opts,
+	}
+
 	// Set colors with defaults if not specified
-	if g.opts.ForegroundColor != nil {
-		qr.ForegroundColor = g.opts.ForegroundColor
-	} else {
+	if qr.ForegroundColor == nil {
 		qr.ForegroundColor = color.Black
 	}
-	if g.opts.BackgroundColor != nil {
-		qr.BackgroundColor = g.opts.BackgroundColor
-	} else {
+	if qr.BackgroundColor == nil {
 		qr.BackgroundColor = color.White
 	}
 
 	return qr, nil
 }
 
+// Bitmap returns the QR code as a 2D array of modules, including a quiet
+// zone of Margin modules on each side. bitmap[y][x] is true for dark
+// modules.
+func (c *Code) Bitmap() [][]bool {
+	symbol := c.symbol
+	margin := c.opts.Margin
+	dim := len(symbol) + 2*margin
+
+	bitmap := make([][]bool, dim)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, dim)
+		if y >= margin && y < margin+len(symbol) {
+			copy(bitmap[y][margin:], symbol[y-margin])
+		}
+	}
+	return bitmap
+}
+
+// PixelSize returns the width and height in pixels of raster output for a
+// requested size. With ModuleSize set the image is exactly ModuleSize
+// pixels per module; otherwise it is size, enlarged to at least one pixel
+// per module.
+func (c *Code) PixelSize(size int) int {
+	dim := len(c.Bitmap())
+	if c.opts.ModuleSize > 0 {
+		return dim * c.opts.ModuleSize
+	}
+	return max(size, dim)
+}
+
 // GeneratePNG generates a QR code and returns it as PNG bytes
 func (g *Generator) GeneratePNG(content string) ([]byte, error) {
 	qr, err := g.Generate(content)
 	if err != nil {
 		return nil, err
 	}
-	return qr.PNG(g.opts.Size)
+	return encodePNG(qr, g.opts.Size)
 }
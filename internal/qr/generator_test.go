@@ -102,21 +102,85 @@ func TestGeneratorGeneratePNG(t *testing.T) {
 	}
 }
 
-func TestErrorCorrectionLevelToQRCode(t *testing.T) {
-	tests := []struct {
-		level    ErrorCorrectionLevel
-		expected int // qrcode.RecoveryLevel values
-	}{
-		{LevelL, 0}, // qrcode.Low
-		{LevelM, 1}, // qrcode.Medium
-		{LevelQ, 2}, // qrcode.High
-		{LevelH, 3}, // qrcode.Highest
+func TestErrorCorrectionLevelString(t *testing.T) {
+	for level, expected := range map[ErrorCorrectionLevel]string{
+		LevelL: "L", LevelM: "M", LevelQ: "Q", LevelH: "H",
+	} {
+		if level.String() != expected {
+			t.Errorf("ErrorCorrectionLevel(%d).String() = %q, want %q", int(level), level.String(), expected)
+		}
+		if parsed, _ := ParseLevel(expected); parsed != level {
+			t.Errorf("ParseLevel(%q) does not round-trip", expected)
+		}
 	}
+}
 
-	for _, tt := range tests {
-		result := tt.level.toQRCode()
-		if int(result) != tt.expected {
-			t.Errorf("ErrorCorrectionLevel(%v).toQRCode() = %v, want %v", tt.level, result, tt.expected)
+func TestGeneratorMargin(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Margin = 0
+	bare, err := NewGenerator(opts).Generate("Hello World")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	symbol := len(bare.Bitmap())
+	// Version 1 symbols are 21 modules wide
+	if symbol != 21 {
+		t.Errorf("Bitmap() with margin 0 is %d modules wide, want 21", symbol)
+	}
+
+	for _, margin := range []int{1, 4, 10} {
+		opts.Margin = margin
+		qr, err := NewGenerator(opts).Generate("Hello World")
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		bitmap := qr.Bitmap()
+		if len(bitmap) != symbol+2*margin || len(bitmap[0]) != symbol+2*margin {
+			t.Errorf("Bitmap() with margin %d is %dx%d, want %d", margin, len(bitmap[0]), len(bitmap), symbol+2*margin)
 		}
+		for i := 0; i < margin; i++ {
+			if bitmap[i][margin] || bitmap[margin][i] {
+				t.Errorf("Bitmap() with margin %d has dark module in quiet zone", margin)
+			}
+		}
+		// Top-left finder pattern corner
+		if !bitmap[margin][margin] {
+			t.Errorf("Bitmap() with margin %d has no finder pattern at (%d, %d)", margin, margin, margin)
+		}
+	}
+
+	opts.Margin = -1
+	if _, err := NewGenerator(opts).Generate("Hello World"); err == nil {
+		t.Error("Generate() with negative margin should fail")
+	}
+}
+
+func TestCodePixelSize(t *testing.T) {
+	opts := DefaultOptions()
+	qr, err := NewGenerator(opts).Generate("Hello World")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	dim := len(qr.Bitmap())
+
+	if got := qr.PixelSize(256); got != 256 {
+		t.Errorf("PixelSize(256) = %d, want 256", got)
+	}
+	if got := qr.PixelSize(5); got != dim {
+		t.Errorf("PixelSize(5) = %d, want %d", got, dim)
+	}
+
+	opts.ModuleSize = 7
+	qr, err = NewGenerator(opts).Generate("Hello World")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if got := qr.PixelSize(256); got != dim*7 {
+		t.Errorf("PixelSize(256) with module size 7 = %d, want %d", got, dim*7)
+	}
+
+	opts.ModuleSize = -1
+	if _, err := NewGenerator(opts).Generate("Hello World"); err == nil {
+		t.Error("Generate() with negative module size should fail")
 	}
 }
@@ -0,0 +1,124 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// renderImage rasterises the QR code into a square image of
+// qr.PixelSize(size) pixels. Square modules take the colour of the nearest
+// module; styled modules are anti-aliased.
+func renderImage(qr *Code, size int) image.Image {
+	bitmap := qr.Bitmap()
+	if qr.opts.Logo != nil {
+		bitmap = clearLogoArea(bitmap, qr.opts)
+	}
+
+	dim := len(bitmap)
+	size = qr.PixelSize(size)
+
+	var img draw.Image
+	if qr.opts.styled() {
+		img = renderShapes(styledShapes(bitmap, qr.opts), dim, size, qr.ForegroundColor, qr.BackgroundColor)
+	} else {
+		img = renderSquares(bitmap, size, qr.ForegroundColor, qr.BackgroundColor)
+	}
+
+	if qr.opts.Logo == nil {
+		return img
+	}
+
+	if _, ok := img.(*image.Paletted); ok {
+		rgba := image.NewNRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+		img = rgba
+	}
+	drawLogo(img, qr, dim, float64(size)/float64(dim))
+	return img
+}
+
+// renderSquares maps each pixel to the nearest module
+func renderSquares(bitmap [][]bool, size int, fg, bg color.Color) *image.Paletted {
+	dim := len(bitmap)
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{bg, fg})
+
+	modulesPerPixel := float64(dim) / float64(size)
+	for y := 0; y < size; y++ {
+		row := bitmap[int(float64(y)*modulesPerPixel)]
+		for x := 0; x < size; x++ {
+			if row[int(float64(x)*modulesPerPixel)] {
+				img.Pix[img.PixOffset(x, y)] = 1
+			}
+		}
+	}
+	return img
+}
+
+// supersample is the number of samples per pixel axis for anti-aliasing
+const supersample = 4
+
+// renderShapes rasterises styled shapes with anti-aliased edges
+func renderShapes(shapes []shape, dim, size int, fg, bg color.Color) *image.NRGBA {
+	unit := float64(size) / float64(dim)
+	coverage := make([]float64, size*size)
+
+	for _, s := range shapes {
+		rr := s.rect
+		x0 := max(0, int(math.Floor(rr.x*unit)))
+		y0 := max(0, int(math.Floor(rr.y*unit)))
+		x1 := min(size, int(math.Ceil((rr.x+rr.w)*unit)))
+		y1 := min(size, int(math.Ceil((rr.y+rr.h)*unit)))
+
+		sign := 1.0
+		if s.hole {
+			sign = -1
+		}
+
+		for py := y0; py < y1; py++ {
+			for px := x0; px < x1; px++ {
+				hits := 0
+				for sy := 0; sy < supersample; sy++ {
+					my := (float64(py) + (float64(sy)+0.5)/supersample) / unit
+					for sx := 0; sx < supersample; sx++ {
+						mx := (float64(px) + (float64(sx)+0.5)/supersample) / unit
+						if rr.contains(mx, my) {
+							hits++
+						}
+					}
+				}
+				coverage[py*size+px] += sign * float64(hits) / (supersample * supersample)
+			}
+		}
+	}
+
+	fgc := color.NRGBAModel.Convert(fg).(color.NRGBA)
+	bgc := color.NRGBAModel.Convert(bg).(color.NRGBA)
+	mix := func(a, b uint8, t float64) uint8 {
+		return uint8(math.Round(float64(a)*(1-t) + float64(b)*t))
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i, c := range coverage {
+		t := min(max(c, 0), 1)
+		img.Pix[i*4+0] = mix(bgc.R, fgc.R, t)
+		img.Pix[i*4+1] = mix(bgc.G, fgc.G, t)
+		img.Pix[i*4+2] = mix(bgc.B, fgc.B, t)
+		img.Pix[i*4+3] = mix(bgc.A, fgc.A, t)
+	}
+	return img
+}
+
+// encodePNG rasterises the QR code and encodes it as PNG
+func encodePNG(qr *Code, size int) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, renderImage(qr, size)); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
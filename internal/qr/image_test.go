@@ -0,0 +1,82 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestRenderImage(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	img := renderImage(qr, 256)
+	if img.Bounds().Dx() != 256 || img.Bounds().Dy() != 256 {
+		t.Errorf("renderImage() size = %v, want 256x256", img.Bounds())
+	}
+
+	// Top-left is quiet zone, and the finder pattern starts after it
+	dim := len(qr.Bitmap())
+	unit := 256 / dim
+	if c := color.GrayModel.Convert(img.At(0, 0)).(color.Gray); c.Y != 0xff {
+		t.Error("renderImage() quiet zone is not background")
+	}
+	finder := DefaultMargin*unit + unit/2 + 1
+	if c := color.GrayModel.Convert(img.At(finder, finder)).(color.Gray); c.Y != 0 {
+		t.Error("renderImage() finder pattern is not foreground")
+	}
+
+	// Too small sizes are enlarged to one pixel per module
+	if small := renderImage(qr, 5); small.Bounds().Dx() != dim {
+		t.Errorf("renderImage() small size = %d, want %d", small.Bounds().Dx(), dim)
+	}
+}
+
+func TestEncodePNG(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	data, err := encodePNG(qr, 128)
+	if err != nil {
+		t.Fatalf("encodePNG() error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("encodePNG() produced invalid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 128 {
+		t.Errorf("encodePNG() width = %d, want 128", img.Bounds().Dx())
+	}
+}
+
+func TestRenderImageModuleSize(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ModuleSize = 5
+	qr, err := NewGenerator(opts).Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	bitmap := qr.Bitmap()
+	img := renderImage(qr, 256)
+	if img.Bounds().Dx() != len(bitmap)*5 {
+		t.Fatalf("renderImage() width = %d, want %d", img.Bounds().Dx(), len(bitmap)*5)
+	}
+
+	// Every pixel matches its module exactly, with no blurred edges
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			dark := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y == 0
+			if dark != bitmap[y/5][x/5] {
+				t.Fatalf("renderImage() pixel (%d, %d) does not match module (%d, %d)", x, y, x/5, y/5)
+			}
+		}
+	}
+}
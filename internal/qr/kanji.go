@@ -0,0 +1,46 @@
+package qr
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// kanjiCode returns the Shift-JIS code of r when kanji mode can hold it,
+// the double-byte ranges 0x8140-0x9FFC and 0xE040-0xEBBF, or 0
+func kanjiCode(r rune) uint16 {
+	if r < utf8.RuneSelf {
+		return 0
+	}
+	b, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(string(r)))
+	if err != nil || len(b) != 2 {
+		return 0
+	}
+	code := uint16(b[0])<<8 | uint16(b[1])
+	if (code >= 0x8140 && code <= 0x9ffc) || (code >= 0xe040 && code <= 0xebbf) {
+		return code
+	}
+	return 0
+}
+
+// packKanji compresses a kanji mode Shift-JIS code into 13 bits: the
+// code less 0x8140 or 0xC140, its high byte times 0xC0 plus its low byte
+func packKanji(code uint16) int {
+	if code <= 0x9ffc {
+		code -= 0x8140
+	} else {
+		code -= 0xc140
+	}
+	return int(code>>8)*0xc0 + int(code&0xff)
+}
+
+// unpackKanji reverses packKanji, returning the two Shift-JIS bytes
+func unpackKanji(v int) [2]byte {
+	code := v/0xc0<<8 | v%0xc0
+	if code < 0x1f00 {
+		code += 0x8140
+	} else {
+		code += 0xc140
+	}
+	return [2]byte{byte(code >> 8), byte(code)}
+}
@@ -0,0 +1,151 @@
+package qr
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKanjiCode(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want uint16
+	}{
+		{'点', 0x935f},
+		{'茗', 0xe4aa},
+		{'あ', 0x82a0},
+		{'①', 0x8740},
+		{'a', 0},
+		{'ｶ', 0}, // half-width katakana is a single Shift-JIS byte
+		{'é', 0},
+		{'😀', 0},
+	}
+
+	for _, tt := range tests {
+		if got := kanjiCode(tt.r); got != tt.want {
+			t.Errorf("kanjiCode(%q) = %#04x, want %#04x", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestPackKanji(t *testing.T) {
+	// The examples of ISO/IEC 18004 section 7.4.6
+	if got := packKanji(0x935f); got != 0x0d9f {
+		t.Errorf("packKanji(0x935f) = %#x, want 0xd9f", got)
+	}
+	if got := packKanji(0xe4aa); got != 0x1aaa {
+		t.Errorf("packKanji(0xe4aa) = %#x, want 0x1aaa", got)
+	}
+
+	for _, code := range []uint16{0x8140, 0x9ffc, 0xe040, 0xebbf, 0x935f, 0xe4aa} {
+		v := packKanji(code)
+		if v >= 1<<13 {
+			t.Errorf("packKanji(%#x) = %#x does not fit 13 bits", code, v)
+		}
+		if b := unpackKanji(v); uint16(b[0])<<8|uint16(b[1]) != code {
+			t.Errorf("unpackKanji(packKanji(%#x)) = % x", code, b)
+		}
+	}
+}
+
+func TestSegmentKanji(t *testing.T) {
+	tests := []struct {
+		content string
+		modes   []int
+	}{
+		{"日本語テキスト", []int{modeKanji}},
+		{"東京都 03-1234-5678", []int{modeKanji, modeAlphanumeric}},
+		{"〒100-0001 東京都千代田区千代田1-1", []int{modeKanji, modeAlphanumeric, modeKanji, modeAlphanumeric}},
+		// Half-width katakana needs byte mode, and with it an ECI header
+		{"ｶﾀｶﾅ漢字", []int{modeECI, modeByte, modeKanji}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			var modes []int
+			for _, s := range textPayload(t, tt.content).segments(1) {
+				modes = append(modes, s.mode)
+			}
+			if !slices.Equal(modes, tt.modes) {
+				t.Errorf("segments(%q) modes = %v, want %v", tt.content, modes, tt.modes)
+			}
+		})
+	}
+}
+
+func TestKanjiSavesSpace(t *testing.T) {
+	p := textPayload(t, "日本語テキスト")
+	version, segs, err := fitVersion(p, LevelM, 0)
+	if err != nil {
+		t.Fatalf("fitVersion() error: %v", err)
+	}
+	if version != 1 || byteModeVersion(p, LevelM) != 2 {
+		t.Errorf("version %d, byte mode %d, want 1 and 2", version, byteModeVersion(p, LevelM))
+	}
+	// 7 characters at 13 bits against 21 UTF-8 bytes and an ECI header
+	if n, _ := segmentsBits(segs, 1); n != 4+8+7*13 {
+		t.Errorf("segments need %d bits, want %d", n, 4+8+7*13)
+	}
+}
+
+func TestKanjiMode(t *testing.T) {
+	tests := []struct {
+		content string
+		charset Charset
+		wantErr string
+	}{
+		{"日本語テキスト", CharsetAuto, ""},
+		{"東京都千代田区", CharsetShiftJIS, ""},
+		{strings.Repeat("漢字", 200), CharsetAuto, ""},
+		{"日本a", CharsetAuto, `'a'`},
+		{"ｶﾀｶﾅ", CharsetAuto, `'ｶ'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ECI = tt.charset
+			opts.Mode = ModeKanji
+			code, err := NewGenerator(opts).Generate(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Generate() error = %v, want it to name %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			if content, _, _, _ := decodeSymbol(t, code.symbol); content != tt.content {
+				t.Errorf("decoded %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
+
+func TestKanjiRoundTrip(t *testing.T) {
+	tests := []struct {
+		content string
+		charset Charset
+	}{
+		{"〒100-0001 東京都千代田区千代田1-1", CharsetAuto},
+		{"ｶﾀｶﾅ漢字 Grüße", CharsetAuto},
+		{"〒100-0001 東京都千代田区千代田1-1 ｶﾀｶﾅ", CharsetShiftJIS},
+		{"漢字 张三 😀", CharsetGB18030},
+		{strings.Repeat("日本語", 400), CharsetAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content+"/"+tt.charset.String(), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ECI = tt.charset
+			code, err := NewGenerator(opts).Generate(tt.content)
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			if content, _, _, _ := decodeSymbol(t, code.symbol); content != tt.content {
+				t.Errorf("decoded %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
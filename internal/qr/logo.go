@@ -0,0 +1,305 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"  // register GIF decoder for logos
+	_ "image/jpeg" // register JPEG decoder for logos
+	"image/png"
+	"math"
+	"os"
+)
+
+// DefaultLogoScale is the default logo width as a fraction of the symbol width
+const DefaultLogoScale = 0.2
+
+// logoCoverageLimit returns the largest fraction of the symbol a logo may
+// cover at the given level. Only half of the nominal recovery capacity is
+// used, leaving the rest for print defects and camera noise.
+func logoCoverageLimit(level ErrorCorrectionLevel) float64 {
+	switch level {
+	case LevelL:
+		return 0.035
+	case LevelM:
+		return 0.075
+	case LevelQ:
+		return 0.125
+	default:
+		return 0.15
+	}
+}
+
+// LogoLevel returns the error correction level to use for a logo covering
+// scale of the symbol width. The level is raised to at least Q, and to H
+// if Q cannot recover the covered area. An error is returned when even
+// the resulting level cannot recover it. Generate may still raise the
+// level, or the version, when the logo hides function patterns or too
+// many codewords of one block.
+func LogoLevel(level ErrorCorrectionLevel, scale float64) (ErrorCorrectionLevel, error) {
+	if scale <= 0 || scale >= 1 {
+		return level, fmt.Errorf("logo scale must be between 0 and 1, got %g", scale)
+	}
+
+	if level < LevelQ {
+		level = LevelQ
+	}
+
+	coverage := scale * scale
+	if coverage > logoCoverageLimit(level) && level < LevelH {
+		level = LevelH
+	}
+	if limit := logoCoverageLimit(level); coverage > limit {
+		return level, fmt.Errorf("logo covers %.0f%% of the code, more than error correction level H can recover (max scale %.2f)",
+			coverage*100, math.Sqrt(limit))
+	}
+
+	return level, nil
+}
+
+// LoadLogo reads a PNG, JPEG or GIF image to use as a logo
+func LoadLogo(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open logo: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+	return img, nil
+}
+
+// logoBox returns the square, in units of the bitmap (including quiet
+// zone), that the logo clears. dim is the bitmap width in modules.
+func logoBox(dim int, opts Options) (x, y, side float64) {
+	symbol := float64(dim - 2*opts.Margin)
+	side = symbol * opts.LogoScale
+	x = (float64(dim) - side) / 2
+	return x, x, side
+}
+
+// drawLogo draws the logo centred in the logo box, preserving its aspect
+// ratio. The modules underneath must already be cleared with
+// clearLogoArea. unit is the size of one module in pixels.
+func drawLogo(img draw.Image, qr *Code, dim int, unit float64) {
+	bx, by, side := logoBox(dim, qr.opts)
+
+	// Keep half a module of background around the logo
+	inner := image.Rect(
+		int(math.Round((bx+0.5)*unit)), int(math.Round((by+0.5)*unit)),
+		int(math.Round((bx+side-0.5)*unit)), int(math.Round((by+side-0.5)*unit)),
+	)
+	if inner.Empty() {
+		return
+	}
+
+	logo := qr.opts.Logo
+	bounds := logo.Bounds()
+	w, h := inner.Dx(), inner.Dy()
+	if bounds.Dx()*h > bounds.Dy()*w {
+		h = max(1, bounds.Dy()*w/bounds.Dx())
+	} else {
+		w = max(1, bounds.Dx()*h/bounds.Dy())
+	}
+	dst := image.Rect(0, 0, w, h).Add(image.Pt(inner.Min.X+(inner.Dx()-w)/2, inner.Min.Y+(inner.Dy()-h)/2))
+
+	draw.Draw(img, dst, scaleImage(logo, w, h), image.Point{}, draw.Over)
+}
+
+// scaleImage resizes src to w x h using bilinear interpolation
+func scaleImage(src image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	sx := float64(b.Dx()) / float64(w)
+	sy := float64(b.Dy()) / float64(h)
+
+	at := func(x, y int) [4]float64 {
+		x = min(max(x, 0), b.Dx()-1)
+		y = min(max(y, 0), b.Dy()-1)
+		// Premultiplied values interpolate correctly across transparent edges
+		r, g, bl, a := src.At(b.Min.X+x, b.Min.Y+y).RGBA()
+		return [4]float64{float64(r), float64(g), float64(bl), float64(a)}
+	}
+
+	for y := 0; y < h; y++ {
+		fy := (float64(y)+0.5)*sy - 0.5
+		y0 := int(math.Floor(fy))
+		ty := fy - float64(y0)
+		for x := 0; x < w; x++ {
+			fx := (float64(x)+0.5)*sx - 0.5
+			x0 := int(math.Floor(fx))
+			tx := fx - float64(x0)
+
+			c00, c10 := at(x0, y0), at(x0+1, y0)
+			c01, c11 := at(x0, y0+1), at(x0+1, y0+1)
+			var v [4]float64
+			for i := range v {
+				top := c00[i]*(1-tx) + c10[i]*tx
+				bottom := c01[i]*(1-tx) + c11[i]*tx
+				v[i] = top*(1-ty) + bottom*ty
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(v[0]), G: uint16(v[1]), B: uint16(v[2]), A: uint16(v[3]),
+			})
+		}
+	}
+	return dst
+}
+
+// clearLogoArea returns a copy of bitmap with the modules under the logo
+// box turned off
+func clearLogoArea(bitmap [][]bool, opts Options) [][]bool {
+	dim := len(bitmap)
+	bx, by, side := logoBox(dim, opts)
+
+	cleared := make([][]bool, dim)
+	for y, row := range bitmap {
+		cleared[y] = append([]bool(nil), row...)
+		cy := float64(y) + 0.5
+		if cy < by || cy > by+side {
+			continue
+		}
+		for x := range row {
+			if cx := float64(x) + 0.5; cx >= bx && cx <= bx+side {
+				cleared[y][x] = false
+			}
+		}
+	}
+	return cleared
+}
+
+// svgLogo returns an SVG element embedding the logo as a PNG data URI,
+// centred in the logo box. Coordinates are in modules.
+func svgLogo(qr *Code, dim int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, qr.opts.Logo); err != nil {
+		return "", fmt.Errorf("failed to encode logo: %w", err)
+	}
+
+	bx, by, side := logoBox(dim, qr.opts)
+	return fmt.Sprintf(`<image x="%s" y="%s" width="%s" height="%s" xlink:href="data:image/png;base64,%s"/>`+"\n",
+		formatNum(bx+0.5), formatNum(by+0.5), formatNum(side-1), formatNum(side-1),
+		base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// logoDamage reports what a logo of the given scale hides in a symbol of
+// version at level: whether it covers a function pattern scanners rely
+// on, and the most codewords it hides in any one block. Alignment
+// patterns other than the bottom-right one are not needed to read the
+// symbol and may be covered.
+func logoDamage(version int, level ErrorCorrectionLevel, scale float64) (bool, int) {
+	dim := symbolSize(version)
+	full := newGrid(dim)
+	for y := range full {
+		for x := range full[y] {
+			full[y][x] = true
+		}
+	}
+	cleared := clearLogoArea(full, Options{LogoScale: scale})
+
+	spare := newGrid(dim)
+	centres := versions[version-1].alignment
+	for _, cy := range centres {
+		for _, cx := range centres {
+			if cx == dim-7 && cy == dim-7 {
+				continue
+			}
+			for y := max(cy-2, 0); y <= cy+2; y++ {
+				for x := max(cx-2, 0); x <= cx+2; x++ {
+					spare[y][x] = true
+				}
+			}
+		}
+	}
+
+	fn := functionPattern(version)
+	for y := range fn {
+		for x := range fn[y] {
+			if fn[y][x] && !cleared[y][x] && !spare[y][x] {
+				return true, 0
+			}
+		}
+	}
+
+	// Map each data module to the block of its codeword, in the order
+	// interleave places them
+	ec := versions[version-1].ec[level]
+	var sizes []int
+	for _, g := range ec.groups {
+		for i := 0; i < g.count; i++ {
+			sizes = append(sizes, g.data)
+		}
+	}
+	var blockOf []int
+	for i := 0; i < sizes[len(sizes)-1]; i++ {
+		for b, n := range sizes {
+			if i < n {
+				blockOf = append(blockOf, b)
+			}
+		}
+	}
+	for i := 0; i < ec.ecPerBlock; i++ {
+		for b := range sizes {
+			blockOf = append(blockOf, b)
+		}
+	}
+
+	hidden := make(map[int]bool)
+	perBlock := make([]int, len(sizes))
+	bit := 0
+	zigzag(fn, func(y, x int) {
+		if cw := bit / 8; cw < len(blockOf) && !cleared[y][x] && !hidden[cw] {
+			hidden[cw] = true
+			perBlock[blockOf[cw]]++
+		}
+		bit++
+	})
+	worst := 0
+	for _, n := range perBlock {
+		worst = max(worst, n)
+	}
+	return false, worst
+}
+
+// logoFit returns the level and version to encode p at with a logo of the
+// given scale: the smallest version of at least minVersion, or exactly
+// version when fixed, where at level or H the logo hides no function
+// pattern and no more codewords per block than error correction recovers
+func logoFit(p payload, level ErrorCorrectionLevel, version int, scale float64) (ErrorCorrectionLevel, int, error) {
+	levels := []ErrorCorrectionLevel{level}
+	if level < LevelH {
+		levels = append(levels, LevelH)
+	}
+	// Content that does not fit at all is reported by encodeSymbol
+	fit, _, err := fitVersion(p, level, version)
+	if err != nil || (version != 0 && fit != version) {
+		return level, version, nil
+	}
+	first, last := fit, version
+	if version == 0 {
+		last = 40
+	}
+
+	for v := first; v <= last; v++ {
+		for _, l := range levels {
+			if fit, _, err := fitVersion(p, l, v); err != nil || fit != v {
+				continue
+			}
+			covers, hidden := logoDamage(v, l, scale)
+			if !covers && hidden <= versions[v-1].ec[l].ecPerBlock/2 {
+				return l, v, nil
+			}
+		}
+	}
+	if version != 0 {
+		return level, 0, fmt.Errorf("logo hides too much of a version %d symbol to recover, use a smaller logo scale or another version", version)
+	}
+	return level, 0, fmt.Errorf("logo hides too much of the symbol to recover at any version, use a smaller logo scale")
+}
@@ -0,0 +1,195 @@
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testLogo returns a solid red square image
+func testLogo(size int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+3] = 0xff, 0xff
+	}
+	return img
+}
+
+func TestLogoLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    ErrorCorrectionLevel
+		scale    float64
+		expected ErrorCorrectionLevel
+		hasError bool
+	}{
+		{"small logo raises L to Q", LevelL, 0.2, LevelQ, false},
+		{"small logo raises M to Q", LevelM, 0.2, LevelQ, false},
+		{"small logo keeps H", LevelH, 0.2, LevelH, false},
+		{"medium logo needs H", LevelM, 0.37, LevelH, false},
+		{"large logo refused", LevelH, 0.5, LevelH, true},
+		{"zero scale", LevelM, 0, LevelM, true},
+		{"full scale", LevelM, 1, LevelM, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := LogoLevel(tt.level, tt.scale)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("LogoLevel(%v, %v) expected error, got nil", tt.level, tt.scale)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LogoLevel(%v, %v) unexpected error: %v", tt.level, tt.scale, err)
+			}
+			if result != tt.expected {
+				t.Errorf("LogoLevel(%v, %v) = %v, want %v", tt.level, tt.scale, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadLogo(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "mkqr-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "logo.png")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create logo: %v", err)
+	}
+	png.Encode(file, testLogo(8))
+	file.Close()
+
+	logo, err := LoadLogo(filename)
+	if err != nil {
+		t.Fatalf("LoadLogo() error: %v", err)
+	}
+	if logo.Bounds().Dx() != 8 {
+		t.Errorf("LoadLogo() width = %d, want 8", logo.Bounds().Dx())
+	}
+
+	if _, err := LoadLogo(filepath.Join(tmpDir, "missing.png")); err == nil {
+		t.Error("LoadLogo() with missing file should fail")
+	}
+
+	notImage := filepath.Join(tmpDir, "logo.txt")
+	os.WriteFile(notImage, []byte("not an image"), 0644)
+	if _, err := LoadLogo(notImage); err == nil {
+		t.Error("LoadLogo() with non-image file should fail")
+	}
+}
+
+func TestClearLogoArea(t *testing.T) {
+	// 10 modules of symbol plus the quiet zone on each side
+	dim := 10 + 2*DefaultMargin
+	bitmap := make([][]bool, dim)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, dim)
+		for x := range bitmap[y] {
+			bitmap[y][x] = true
+		}
+	}
+
+	cleared := clearLogoArea(bitmap, Options{Margin: DefaultMargin, LogoScale: 0.4})
+	center := dim / 2
+	if cleared[center][center] {
+		t.Error("clearLogoArea() did not clear the centre module")
+	}
+	if !cleared[DefaultMargin][DefaultMargin] {
+		t.Error("clearLogoArea() cleared a corner module")
+	}
+	if !bitmap[center][center] {
+		t.Error("clearLogoArea() modified the input bitmap")
+	}
+}
+
+func TestScaleImage(t *testing.T) {
+	scaled := scaleImage(testLogo(4), 10, 6)
+	if scaled.Bounds().Dx() != 10 || scaled.Bounds().Dy() != 6 {
+		t.Fatalf("scaleImage() size = %v, want 10x6", scaled.Bounds())
+	}
+	if c := scaled.NRGBAAt(5, 3); c != (color.NRGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("scaleImage() centre pixel = %v, want red", c)
+	}
+}
+
+func TestGenerateWithLogo(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Logo = testLogo(16)
+	gen := NewGenerator(opts)
+
+	qr, err := gen.Generate("https://example.com")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if qr.opts.Level != LevelQ {
+		t.Errorf("Generate() with logo level = %v, want %v", qr.opts.Level, LevelQ)
+	}
+
+	img := renderImage(qr, 256)
+	if r, g, b, _ := img.At(128, 128).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Error("renderImage() did not draw the logo in the centre")
+	}
+
+	svg, err := ToSVG(qr, 256)
+	if err != nil {
+		t.Fatalf("ToSVG() error: %v", err)
+	}
+	if !strings.Contains(string(svg), `xlink:href="data:image/png;base64,`) {
+		t.Error("ToSVG() did not embed the logo")
+	}
+
+	if _, err := ToPDF(qr, DefaultPDFConfig()); err == nil {
+		t.Error("ToPDF() with logo should fail")
+	}
+
+	opts.LogoScale = 0.6
+	if _, err := NewGenerator(opts).Generate("https://example.com"); err == nil {
+		t.Error("Generate() with oversized logo should fail")
+	}
+}
+
+func TestLogoRoundTrip(t *testing.T) {
+	const content = "12345"
+	for version := 1; version <= 5; version++ {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Logo = testLogo(16)
+			opts.Version = version
+
+			// The largest scale Generate accepts at this version, in steps
+			// of a hundredth below the H limit
+			var qr *Code
+			for scale := math.Floor(math.Sqrt(logoCoverageLimit(LevelH))*100) / 100; scale > 0; scale -= 0.01 {
+				opts.LogoScale = scale
+				if code, err := NewGenerator(opts).Generate(content); err == nil {
+					qr = code
+					break
+				}
+			}
+			if qr == nil {
+				t.Fatal("Generate() accepted no logo scale")
+			}
+
+			results, err := Decode(renderImage(qr, 512))
+			if err != nil {
+				t.Fatalf("Decode() at scale %.2f error: %v", qr.opts.LogoScale, err)
+			}
+			if results[0].Content != content {
+				t.Errorf("Decode() = %q, want %q", results[0].Content, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// MicroMargin is the quiet zone width required around Micro QR symbols,
+// in modules
+const MicroMargin = 2
+
+// microVersions holds ISO/IEC 18004 table 7 for Micro QR symbols M1 to
+// M4: data capacity in bits and error correction codewords at levels L, M
+// and Q, 0 where the level is not available. M1 only detects errors and
+// is listed under L.
+var microVersions = [4]struct {
+	dataBits [3]int
+	ec       [3]int
+}{
+	{[3]int{20, 0, 0}, [3]int{2, 0, 0}},
+	{[3]int{40, 32, 0}, [3]int{5, 6, 0}},
+	{[3]int{84, 68, 0}, [3]int{6, 8, 0}},
+	{[3]int{128, 112, 80}, [3]int{8, 10, 14}},
+}
+
+// microModes maps Micro QR mode indicators to segment modes
+var microModes = [4]int{modeNumeric, modeAlphanumeric, modeByte, modeKanji}
+
+// microMasks maps the four Micro QR mask patterns to the QR mask patterns
+// with the same formula
+var microMasks = [4]int{1, 4, 6, 7}
+
+// microCountBits returns the character count field width of a mode in
+// Micro QR version M1 to M4, or 0 when the version cannot hold the mode
+func microCountBits(mode, version int) int {
+	switch mode {
+	case modeNumeric:
+		return [4]int{3, 4, 5, 6}[version-1]
+	case modeAlphanumeric:
+		return [4]int{0, 3, 4, 5}[version-1]
+	case modeByte:
+		return [4]int{0, 0, 4, 5}[version-1]
+	case modeKanji:
+		return [4]int{0, 0, 3, 4}[version-1]
+	default:
+		return 0
+	}
+}
+
+// microModeIndicator returns the Micro QR mode indicator of a mode
+func microModeIndicator(mode int) int {
+	for i, m := range microModes {
+		if m == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+// microSize returns the width in modules of Micro QR version M1 to M4
+func microSize(version int) int {
+	return 9 + 2*version
+}
+
+// microLevelOK reports whether Micro QR version M1 to M4 offers level
+func microLevelOK(version int, level ErrorCorrectionLevel) bool {
+	return level <= LevelQ && microVersions[version-1].dataBits[level] > 0
+}
+
+// microSymbolNumber returns the three-bit symbol number of the format
+// information: M1, then M2 to M4 at each of their levels in turn
+func microSymbolNumber(version int, level ErrorCorrectionLevel) int {
+	if version == 1 {
+		return 0
+	}
+	return 2*version - 3 + int(level)
+}
+
+// microFormatBits returns the masked 15-bit format information of a
+// Micro QR symbol
+func microFormatBits(version int, level ErrorCorrectionLevel, mask int) uint32 {
+	data := uint32(microSymbolNumber(version, level)<<2 | mask)
+	return (data<<10 | bchRemainder(data<<10, 0x537)) ^ 0x4445
+}
+
+// microFunctionPattern marks the finder pattern with its separator, the
+// format information and the timing patterns of a Micro QR symbol
+func microFunctionPattern(version int) [][]bool {
+	dim := microSize(version)
+	fn := newGrid(dim)
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			fn[y][x] = (x <= 8 && y <= 8) || x == 0 || y == 0
+		}
+	}
+	return fn
+}
+
+// fitMicro returns the smallest Micro QR version of at least minVersion
+// that holds p at level, with the segments encoding it
+func fitMicro(p payload, level ErrorCorrectionLevel, minVersion int) (int, []segment, error) {
+	if level > LevelQ {
+		return 0, nil, fmt.Errorf("Micro QR supports error correction levels L, M and Q, not %s", level)
+	}
+	for version := max(minVersion, 1); version <= 4; version++ {
+		if !microLevelOK(version, level) {
+			continue
+		}
+		segs := segmentContent(p, -version)
+		if segs == nil {
+			continue
+		}
+		if n, ok := segmentsBits(segs, -version); ok && n <= microVersions[version-1].dataBits[level] {
+			return version, segs, nil
+		}
+	}
+
+	capacity := microVersions[3].dataBits[level]
+	if n, ok := segmentsBits(segmentContent(p, -4), -4); ok {
+		return 0, nil, fmt.Errorf("content too long for Micro QR: needs %d bits, M4-%s holds %d", n, level, capacity)
+	}
+	return 0, nil, fmt.Errorf("content too long for Micro QR: M4-%s holds %d bits", level, capacity)
+}
+
+// microCodewords encodes segs, pads them to the data capacity and appends
+// the error correction codewords. M1 and M3 end their data with a 4-bit
+// codeword, which takes part in error correction as its high nibble and
+// is placed in the symbol as 4 bits; the result is the bit sequence to
+// place.
+func microCodewords(segs []segment, version int, level ErrorCorrectionLevel) []byte {
+	capacity := microVersions[version-1].dataBits[level]
+	w := &bitWriter{}
+	for _, s := range segs {
+		s.write(w, -version)
+	}
+
+	// Terminator of 3, 5, 7 or 9 zero bits, zeros to the codeword boundary
+	// and pad codewords, the final 4-bit codeword being zero
+	w.write(0, min(2*version+1, capacity-w.n))
+	w.write(0, min((8-w.n%8)%8, capacity-w.n))
+	for i := 0; capacity-w.n >= 8; i++ {
+		w.write([2]int{0xec, 0x11}[i%2], 8)
+	}
+	w.write(0, capacity-w.n)
+
+	data := w.data
+	ec := rsEncode(data, microVersions[version-1].ec[level])
+	placed := &bitWriter{}
+	for i, c := range data {
+		if i == len(data)-1 && capacity%8 == 4 {
+			placed.write(int(c>>4), 4)
+		} else {
+			placed.write(int(c), 8)
+		}
+	}
+	for _, c := range ec {
+		placed.write(int(c), 8)
+	}
+	return placed.data
+}
+
+// encodeMicro encodes p in a Micro QR symbol of the given version M1 to
+// M4 and mask 0-3. A version of 0 picks the smallest that fits and
+// AutoMask the mask scoring best. It returns the symbol, without a quiet
+// zone, and the version and mask used.
+func encodeMicro(p payload, level ErrorCorrectionLevel, version, mask int) ([][]bool, int, int, error) {
+	if len(p.data) == 0 {
+		return nil, 0, 0, errNoData
+	}
+	if version != 0 && level <= LevelQ && !microLevelOK(version, level) {
+		return nil, 0, 0, fmt.Errorf("M%d does not offer error correction level %s", version, level)
+	}
+	fit, segs, err := fitMicro(p, level, version)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if version != 0 && fit != version {
+		return nil, 0, 0, fmt.Errorf("content needs M%d at level %s, it does not fit in M%d", fit, level, version)
+	}
+
+	dim := microSize(fit)
+	fn := microFunctionPattern(fit)
+	base := newGrid(dim)
+	for dy := 0; dy < 7; dy++ {
+		for dx := 0; dx < 7; dx++ {
+			base[dy][dx] = max(abs(dx-3), abs(dy-3)) != 2
+		}
+	}
+	for i := 8; i < dim; i++ {
+		base[0][i] = i%2 == 0
+		base[i][0] = i%2 == 0
+	}
+	placeCodewords(base, fn, microCodewords(segs, fit, level))
+
+	if mask != AutoMask {
+		return applyMicroMask(base, fn, fit, level, mask), fit, mask, nil
+	}
+	var best [][]bool
+	bestMask, bestScore := 0, -1
+	for m := 0; m < 4; m++ {
+		symbol := applyMicroMask(base, fn, fit, level, m)
+		if s := microScore(symbol); s > bestScore {
+			best, bestMask, bestScore = symbol, m, s
+		}
+	}
+	return best, fit, bestMask, nil
+}
+
+// applyMicroMask returns a copy of m with the data modules masked and the
+// format information drawn: bits 0-7 down column 8 from row 1, bits 14-7
+// along row 8 from column 1
+func applyMicroMask(m, fn [][]bool, version int, level ErrorCorrectionLevel, mask int) [][]bool {
+	symbol := newGrid(len(m))
+	for y := range m {
+		for x := range m[y] {
+			symbol[y][x] = m[y][x]
+			if !fn[y][x] && maskBit(microMasks[mask], y, x) {
+				symbol[y][x] = !symbol[y][x]
+			}
+		}
+	}
+
+	format := microFormatBits(version, level, mask)
+	for i := 0; i < 8; i++ {
+		symbol[i+1][8] = format>>i&1 == 1
+		symbol[8][i+1] = format>>(14-i)&1 == 1
+	}
+	return symbol
+}
+
+// microScore rates a masked Micro QR symbol by the dark modules along its
+// right and bottom edges, which keep the symbol outline visible; higher
+// is better
+func microScore(m [][]bool) int {
+	dim := len(m)
+	right, bottom := 0, 0
+	for i := 1; i < dim; i++ {
+		if m[i][dim-1] {
+			right++
+		}
+		if m[dim-1][i] {
+			bottom++
+		}
+	}
+	if right <= bottom {
+		return right*16 + bottom
+	}
+	return bottom*16 + right
+}
+
+// errMicroFormat is returned when the format information of a Micro QR
+// symbol cannot be read
+var errMicroFormat = errors.New("unreadable Micro QR format information")
+
+// decodeMicro decodes a Micro QR symbol without quiet zone, m[y][x] being
+// true for dark modules. It returns the content, version M1 to M4 and
+// level.
+func decodeMicro(m [][]bool) (string, int, ErrorCorrectionLevel, error) {
+	var format uint32
+	for i := 0; i < 8; i++ {
+		if m[i+1][8] {
+			format |= 1 << i
+		}
+		if m[8][i+1] {
+			format |= 1 << (14 - i)
+		}
+	}
+
+	best, version, level, mask := 4, 0, LevelL, 0
+	for v := 1; v <= 4; v++ {
+		for l := LevelL; l <= LevelQ; l++ {
+			if !microLevelOK(v, l) {
+				continue
+			}
+			for k := 0; k < 4; k++ {
+				if d := bits.OnesCount32(format ^ microFormatBits(v, l, k)); d < best {
+					best, version, level, mask = d, v, l, k
+				}
+			}
+		}
+	}
+	if best > 3 {
+		return "", 0, 0, errMicroFormat
+	}
+	if microSize(version) != len(m) {
+		return "", 0, 0, fmt.Errorf("M%d does not match symbol width %d", version, len(m))
+	}
+
+	// Unmask and read the modules in placement order
+	w := &bitWriter{}
+	zigzag(microFunctionPattern(version), func(y, x int) {
+		if m[y][x] != maskBit(microMasks[mask], y, x) {
+			w.write(1, 1)
+		} else {
+			w.write(0, 1)
+		}
+	})
+	r := &bitReader{data: w.data}
+
+	capacity := microVersions[version-1].dataBits[level]
+	ecLen := microVersions[version-1].ec[level]
+	block := make([]byte, 0, (capacity+7)/8+ecLen)
+	for n := capacity; n > 0; n -= 8 {
+		v, err := r.read(min(n, 8))
+		if err != nil {
+			return "", 0, 0, err
+		}
+		block = append(block, byte(v<<(8-min(n, 8))))
+	}
+	for i := 0; i < ecLen; i++ {
+		v, err := r.read(8)
+		if err != nil {
+			return "", 0, 0, err
+		}
+		block = append(block, byte(v))
+	}
+	if _, err := rsCorrect(block, ecLen); err != nil {
+		return "", 0, 0, err
+	}
+
+	content, err := parseSegments(block[:len(block)-ecLen], -version)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return content, version, level, nil
+}
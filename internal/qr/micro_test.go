@@ -0,0 +1,206 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestMicroCodewords(t *testing.T) {
+	// 01234567 at M2-L, the Micro QR example of ISO/IEC 18004 annex I
+	segs := segmentContent(textPayload(t, "01234567"), -2)
+	got := microCodewords(segs, 2, LevelL)
+	want := []byte{0x40, 0x18, 0xac, 0xc3, 0x00, 0x86, 0x0d, 0x22, 0xae, 0x30}
+	if !bytes.Equal(got, want) {
+		t.Errorf("microCodewords() = % x, want % x", got, want)
+	}
+}
+
+func TestMicroFormatBits(t *testing.T) {
+	tests := []struct {
+		version int
+		level   ErrorCorrectionLevel
+		mask    int
+		want    uint32
+	}{
+		{1, LevelL, 0, 0x4445},
+		{1, LevelL, 1, 0x4172},
+		{1, LevelL, 3, 0x4b1c},
+		{2, LevelL, 0, 0x55ae},
+		{2, LevelM, 0, 0x6793},
+	}
+
+	for _, tt := range tests {
+		if got := microFormatBits(tt.version, tt.level, tt.mask); got != tt.want {
+			t.Errorf("microFormatBits(M%d-%s, %d) = %#x, want %#x", tt.version, tt.level, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestFitMicro(t *testing.T) {
+	tests := []struct {
+		content string
+		level   ErrorCorrectionLevel
+		want    int
+	}{
+		{"12345", LevelL, 1},
+		{"123456", LevelL, 2},
+		{"12345", LevelM, 2},
+		{"HELLO", LevelL, 2},
+		{"hello", LevelL, 3},
+		{strings.Repeat("1", 23), LevelL, 3},
+		{strings.Repeat("1", 24), LevelL, 4},
+		{strings.Repeat("1", 35), LevelL, 4},
+		{strings.Repeat("A", 21), LevelL, 4},
+		{strings.Repeat("a", 15), LevelL, 4},
+		{strings.Repeat("1", 21), LevelQ, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content+"/"+tt.level.String(), func(t *testing.T) {
+			got, _, err := fitMicro(textPayload(t, tt.content), tt.level, 0)
+			if err != nil {
+				t.Fatalf("fitMicro() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("fitMicro() = M%d, want M%d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeMicroRoundTrip(t *testing.T) {
+	tests := []struct {
+		content string
+		level   ErrorCorrectionLevel
+		version int
+		mask    int
+	}{
+		{"12345", LevelL, 0, AutoMask},
+		{"01234567", LevelL, 2, 1},
+		{"HELLO", LevelM, 0, 3},
+		{"tel:+15551234", LevelL, 0, AutoMask},
+		{"TAG 0042-A", LevelM, 4, 2},
+		{"Grüße", LevelL, 0, 0},
+		{"点茗", LevelM, 0, AutoMask},
+		{strings.Repeat("1", 35), LevelL, 0, AutoMask},
+		{strings.Repeat("1", 21), LevelQ, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			symbol, version, mask, err := encodeMicro(textPayload(t, tt.content), tt.level, tt.version, tt.mask)
+			if err != nil {
+				t.Fatalf("encodeMicro() error: %v", err)
+			}
+			if len(symbol) != microSize(version) {
+				t.Errorf("symbol is %d modules wide, want %d", len(symbol), microSize(version))
+			}
+			if tt.version != 0 && version != tt.version {
+				t.Errorf("version = M%d, want M%d", version, tt.version)
+			}
+			if tt.mask != AutoMask && mask != tt.mask {
+				t.Errorf("mask = %d, want %d", mask, tt.mask)
+			}
+
+			content, gotVersion, gotLevel, err := decodeMicro(symbol)
+			if err != nil {
+				t.Fatalf("decodeMicro() error: %v", err)
+			}
+			if content != tt.content || gotVersion != version || gotLevel != tt.level {
+				t.Errorf("decoded %q at M%d-%s, want %q at M%d-%s", content, gotVersion, gotLevel, tt.content, version, tt.level)
+			}
+		})
+	}
+}
+
+func TestEncodeMicroErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		level   ErrorCorrectionLevel
+		version int
+		wantErr string
+	}{
+		{"too long", strings.Repeat("x", 16), LevelL, 0, "too long for Micro QR"},
+		{"level H", "12345", LevelH, 0, "levels L, M and Q"},
+		{"version too small", "HELLO", LevelL, 1, "needs M2"},
+		{"level not offered", "12345", LevelQ, 3, "M3 does not offer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := encodeMicro(textPayload(t, tt.content), tt.level, tt.version, AutoMask)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("encodeMicro() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMicroAutoMask(t *testing.T) {
+	p := textPayload(t, "tel:+15551234")
+	_, version, auto, err := encodeMicro(p, LevelL, 0, AutoMask)
+	if err != nil {
+		t.Fatalf("encodeMicro() error: %v", err)
+	}
+
+	// The first mask reaching the highest score wins
+	bestMask, bestScore := 0, -1
+	for mask := 0; mask < 4; mask++ {
+		symbol, _, _, err := encodeMicro(p, LevelL, version, mask)
+		if err != nil {
+			t.Fatalf("encodeMicro() error: %v", err)
+		}
+		if s := microScore(symbol); s > bestScore {
+			bestMask, bestScore = mask, s
+		}
+	}
+	if auto != bestMask {
+		t.Errorf("auto mask = %d, want %d with score %d", auto, bestMask, bestScore)
+	}
+}
+
+func TestGenerateMicro(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"default", func(o *Options) {}},
+		{"styled", func(o *Options) { o.ModuleStyle, o.EyeStyle = StyleDot, EyeCircle }},
+		{"inverted", func(o *Options) { o.ForegroundColor, o.BackgroundColor = color.White, color.Black }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Micro = true
+			opts.Margin = MicroMargin
+			tt.modify(&opts)
+			code, err := NewGenerator(opts).Generate("TAG 0042-A")
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			if !code.Micro || code.Version != 3 || code.ByteModeVersion != 0 {
+				t.Errorf("Generate() = M%d, micro %v, byte mode %d, want M3", code.Version, code.Micro, code.ByteModeVersion)
+			}
+			if err := Verify(code, "TAG 0042-A", 256); err != nil {
+				t.Errorf("Verify() error: %v", err)
+			}
+		})
+	}
+
+	for _, bad := range []func(*Options){
+		func(o *Options) { o.Version = 5 },
+		func(o *Options) { o.Mask = 4 },
+		func(o *Options) { o.ECI = CharsetUTF8 },
+	} {
+		opts := DefaultOptions()
+		opts.Micro = true
+		bad(&opts)
+		if _, err := NewGenerator(opts).Generate("TAG"); err == nil {
+			t.Errorf("Generate() with %+v should fail", opts)
+		}
+	}
+}
@@ -3,11 +3,10 @@ package qr
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/skip2/go-qrcode"
 )
 
 // OutputFormat represents the output format
@@ -17,40 +16,100 @@ const (
 	FormatTerminal OutputFormat = "terminal"
 	FormatPNG      OutputFormat = "png"
 	FormatBase64   OutputFormat = "base64"
+	FormatSVG      OutputFormat = "svg"
+	FormatPDF      OutputFormat = "pdf"
+	FormatDataURI  OutputFormat = "data-uri"
 )
 
+// ParseFormat parses an output format name
+func ParseFormat(s string) (OutputFormat, error) {
+	switch strings.ToLower(s) {
+	case "terminal", "term":
+		return FormatTerminal, nil
+	case "png":
+		return FormatPNG, nil
+	case "svg":
+		return FormatSVG, nil
+	case "pdf":
+		return FormatPDF, nil
+	case "base64", "b64":
+		return FormatBase64, nil
+	case "data-uri", "datauri", "uri":
+		return FormatDataURI, nil
+	default:
+		return "", fmt.Errorf("invalid output format: %s (use terminal, png, svg, pdf, base64, or data-uri)", s)
+	}
+}
+
 // DetectFormat detects output format from filename
 func DetectFormat(filename string) OutputFormat {
 	ext := strings.ToLower(filepath.Ext(filename))
 	switch ext {
 	case ".png":
 		return FormatPNG
+	case ".svg":
+		return FormatSVG
+	case ".pdf":
+		return FormatPDF
 	default:
 		return FormatPNG // Default to PNG for files
 	}
 }
 
 // SavePNG saves the QR code as a PNG file
-func SavePNG(qr *qrcode.QRCode, filename string, size int) error {
-	// Ensure directory exists
+func SavePNG(qr *Code, filename string, size int) error {
+	data, err := encodePNG(qr, size)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureDir(filename); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write PNG file: %w", err)
+	}
+	return nil
+}
+
+// ensureDir creates the parent directory of filename if needed
+func ensureDir(filename string) error {
 	dir := filepath.Dir(filename)
 	if dir != "." && dir != "" {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create directory: %w", err)
 		}
 	}
+	return nil
+}
 
-	if err := qr.WriteFile(size, filename); err != nil {
-		return fmt.Errorf("failed to write PNG file: %w", err)
+// WritePNG writes the QR code as PNG image data to w
+func WritePNG(w io.Writer, qr *Code, size int) error {
+	data, err := encodePNG(qr, size)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write PNG: %w", err)
 	}
 	return nil
 }
 
 // ToBase64 returns the QR code as a base64-encoded PNG string
-func ToBase64(qr *qrcode.QRCode, size int) (string, error) {
-	png, err := qr.PNG(size)
+func ToBase64(qr *Code, size int) (string, error) {
+	png, err := encodePNG(qr, size)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate PNG: %w", err)
 	}
 	return base64.StdEncoding.EncodeToString(png), nil
 }
+
+// ToDataURI returns the QR code as a PNG data URI (data:image/png;base64,...)
+func ToDataURI(qr *Code, size int) (string, error) {
+	encoded, err := ToBase64(qr, size)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + encoded, nil
+}
@@ -1,8 +1,11 @@
 package qr
 
 import (
+	"bytes"
+	"encoding/base64"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -14,9 +17,12 @@ func TestDetectFormat(t *testing.T) {
 		{"output.png", FormatPNG},
 		{"output.PNG", FormatPNG},
 		{"path/to/file.png", FormatPNG},
+		{"output.svg", FormatSVG},
+		{"output.SVG", FormatSVG},
+		{"handout.pdf", FormatPDF},
 		{"output.jpg", FormatPNG},  // defaults to PNG
-		{"output", FormatPNG},       // defaults to PNG
-		{"output.jpeg", FormatPNG},  // defaults to PNG
+		{"output", FormatPNG},      // defaults to PNG
+		{"output.jpeg", FormatPNG}, // defaults to PNG
 	}
 
 	for _, tt := range tests {
@@ -104,3 +110,80 @@ func TestToBase64(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected OutputFormat
+		hasError bool
+	}{
+		{"terminal", FormatTerminal, false},
+		{"PNG", FormatPNG, false},
+		{"svg", FormatSVG, false},
+		{"pdf", FormatPDF, false},
+		{"base64", FormatBase64, false},
+		{"data-uri", FormatDataURI, false},
+		{"datauri", FormatDataURI, false},
+		{"gif", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseFormat(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParseFormat(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseFormat(%q) unexpected error: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, qr, 256); err != nil {
+		t.Fatalf("WritePNG() error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Error("WritePNG() did not write PNG data")
+	}
+}
+
+func TestToDataURI(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	uri, err := ToDataURI(qr, 256)
+	if err != nil {
+		t.Fatalf("ToDataURI() error: %v", err)
+	}
+
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(uri, prefix) {
+		t.Fatalf("ToDataURI() missing prefix: %.40s", uri)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, prefix))
+	if err != nil {
+		t.Fatalf("ToDataURI() payload is not base64: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("ToDataURI() payload is not a PNG")
+	}
+}
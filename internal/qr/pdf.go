@@ -0,0 +1,309 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Placement controls where the QR code is positioned on the page
+type Placement string
+
+const (
+	PlaceCenter Placement = "center" // Centered on the page
+	PlaceTop    Placement = "top"    // Centered horizontally, at the top margin
+)
+
+// ParsePlacement parses a string into Placement
+func ParsePlacement(s string) (Placement, error) {
+	switch strings.ToLower(s) {
+	case "center", "centre", "":
+		return PlaceCenter, nil
+	case "top":
+		return PlaceTop, nil
+	default:
+		return PlaceCenter, fmt.Errorf("invalid placement: %s (use center or top)", s)
+	}
+}
+
+// PDFConfig configures PDF output. All lengths are in millimetres.
+type PDFConfig struct {
+	PageWidth  float64
+	PageHeight float64
+	Margin     float64
+	Width      float64 // QR code width; 0 fills the space inside the margins
+	Placement  Placement
+	Caption    string // Optional text printed below the code
+}
+
+// DefaultPDFConfig returns an A4 page with 15mm margins
+func DefaultPDFConfig() PDFConfig {
+	return PDFConfig{
+		PageWidth:  210,
+		PageHeight: 297,
+		Margin:     15,
+		Placement:  PlaceCenter,
+	}
+}
+
+// pageSizes maps named paper sizes to width and height in millimetres
+var pageSizes = map[string][2]float64{
+	"a3":     {297, 420},
+	"a4":     {210, 297},
+	"a5":     {148, 210},
+	"a6":     {105, 148},
+	"letter": {215.9, 279.4},
+	"legal":  {215.9, 355.6},
+}
+
+// ParsePageSize parses a named page size (A3-A6, Letter, Legal) or a
+// custom "WIDTHxHEIGHT" size in millimetres, e.g. "100x150"
+func ParsePageSize(s string) (width, height float64, err error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if size, ok := pageSizes[lower]; ok {
+		return size[0], size[1], nil
+	}
+
+	w, h, ok := strings.Cut(strings.TrimSuffix(lower, "mm"), "x")
+	if ok {
+		width, errW := strconv.ParseFloat(strings.TrimSpace(w), 64)
+		height, errH := strconv.ParseFloat(strings.TrimSpace(h), 64)
+		if errW == nil && errH == nil && width > 0 && height > 0 {
+			return width, height, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("invalid page size: %s (use A3, A4, A5, A6, Letter, Legal, or WIDTHxHEIGHT in mm)", s)
+}
+
+const (
+	ptPerMM         = 72 / 25.4
+	captionFontSize = 12.0 // points
+)
+
+// WritePDF writes the QR code as a single-page vector PDF to w
+func WritePDF(w io.Writer, qr *Code, cfg PDFConfig) error {
+	if qr.opts.Logo != nil {
+		return fmt.Errorf("logo overlay is only supported for PNG and SVG output")
+	}
+	if qr.opts.styled() {
+		return fmt.Errorf("module and eye styles are only supported for PNG and SVG output")
+	}
+
+	content, err := pdfContent(qr, cfg)
+	if err != nil {
+		return err
+	}
+
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+	objects = append(objects, "<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+
+	resources := "<< >>"
+	if cfg.Caption != "" {
+		resources = "<< /Font << /F1 5 0 R >> >>"
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents 4 0 R >>",
+		formatNum(cfg.PageWidth*ptPerMM), formatNum(cfg.PageHeight*ptPerMM), resources))
+	objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	if cfg.Caption != "" {
+		objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// ToPDF returns the QR code as a PDF document
+func ToPDF(qr *Code, cfg PDFConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, qr, cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SavePDF saves the QR code as a PDF file
+func SavePDF(qr *Code, filename string, cfg PDFConfig) error {
+	data, err := ToPDF(qr, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureDir(filename); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+	return nil
+}
+
+// pdfContent builds the page content stream: background, modules and caption
+func pdfContent(qr *Code, cfg PDFConfig) (string, error) {
+	if cfg.PageWidth <= 0 || cfg.PageHeight <= 0 {
+		return "", fmt.Errorf("page size must be positive, got %gx%gmm", cfg.PageWidth, cfg.PageHeight)
+	}
+	if cfg.Margin < 0 {
+		return "", fmt.Errorf("margin cannot be negative, got %gmm", cfg.Margin)
+	}
+
+	captionSpace := 0.0
+	if cfg.Caption != "" {
+		captionSpace = 2 * captionFontSize / ptPerMM
+	}
+
+	availW := cfg.PageWidth - 2*cfg.Margin
+	availH := cfg.PageHeight - 2*cfg.Margin - captionSpace
+	maxWidth := min(availW, availH)
+	if maxWidth <= 0 {
+		return "", fmt.Errorf("margins leave no room on a %gx%gmm page", cfg.PageWidth, cfg.PageHeight)
+	}
+
+	width := cfg.Width
+	if width == 0 {
+		width = maxWidth
+	} else if width < 0 || width > maxWidth {
+		return "", fmt.Errorf("QR width %gmm does not fit on the page (max %.1fmm)", width, maxWidth)
+	}
+
+	// Position in mm from the bottom-left corner, as PDF expects
+	x := (cfg.PageWidth - width) / 2
+	var y float64
+	switch cfg.Placement {
+	case PlaceTop:
+		y = cfg.PageHeight - cfg.Margin - width
+	default:
+		y = (cfg.PageHeight-width-captionSpace)/2 + captionSpace
+	}
+
+	bitmap := qr.Bitmap()
+	dim := len(bitmap)
+	module := width * ptPerMM / float64(dim)
+	originX := x * ptPerMM
+	originY := y * ptPerMM
+
+	var b strings.Builder
+
+	bg := qr.BackgroundColor
+	if bg == nil {
+		bg = color.White
+	}
+	if _, _, _, a := bg.RGBA(); a != 0 {
+		fmt.Fprintf(&b, "%s rg\n%s %s %s %s re f\n", pdfColor(bg),
+			formatNum(originX), formatNum(originY), formatNum(width*ptPerMM), formatNum(width*ptPerMM))
+	}
+
+	fg := qr.ForegroundColor
+	if fg == nil {
+		fg = color.Black
+	}
+	fmt.Fprintf(&b, "%s rg\n", pdfColor(fg))
+
+	// One rectangle per horizontal run of dark modules; bitmap rows
+	// run top-down while PDF y grows upwards
+	for row, line := range bitmap {
+		top := originY + float64(dim-row-1)*module
+		for col := 0; col < len(line); {
+			if !line[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(line) && line[col] {
+				col++
+			}
+			fmt.Fprintf(&b, "%s %s %s %s re\n",
+				formatNum(originX+float64(start)*module), formatNum(top),
+				formatNum(float64(col-start)*module), formatNum(module))
+		}
+	}
+	b.WriteString("f\n")
+
+	if cfg.Caption != "" {
+		text, textWidth := pdfText(cfg.Caption)
+		textX := (cfg.PageWidth*ptPerMM - textWidth*captionFontSize/1000) / 2
+		textY := originY - 1.5*captionFontSize
+		fmt.Fprintf(&b, "0 0 0 rg\nBT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+			formatNum(captionFontSize), formatNum(textX), formatNum(textY), text)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// formatNum formats a number compactly for PDF operators and SVG paths,
+// rounded to three decimal places
+func formatNum(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// pdfColor formats c as PDF RGB operands (0-1 range)
+func pdfColor(c color.Color) string {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("%s %s %s",
+		strconv.FormatFloat(float64(nrgba.R)/0xff, 'f', 3, 64),
+		strconv.FormatFloat(float64(nrgba.G)/0xff, 'f', 3, 64),
+		strconv.FormatFloat(float64(nrgba.B)/0xff, 'f', 3, 64))
+}
+
+// helveticaWidths holds glyph widths for ASCII 32-126 in 1/1000 em
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfText escapes s as a WinAnsi PDF string literal and returns its width
+// in 1/1000 em. Characters outside Latin-1 are replaced with '?'.
+func pdfText(s string) (string, float64) {
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		if r > 0xff || r < 0x20 || (r >= 0x7f && r < 0xa0) {
+			r = '?'
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+		if r <= 0x7e {
+			width += helveticaWidths[r-0x20]
+		} else {
+			width += 556
+		}
+	}
+	return b.String(), float64(width)
+}
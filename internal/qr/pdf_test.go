@@ -0,0 +1,169 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParsePageSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		width    float64
+		height   float64
+		hasError bool
+	}{
+		{"A4", 210, 297, false},
+		{"a5", 148, 210, false},
+		{"Letter", 215.9, 279.4, false},
+		{"100x150", 100, 150, false},
+		{"100x150mm", 100, 150, false},
+		{"62.5X29", 62.5, 29, false},
+		{"B7", 0, 0, true},
+		{"0x100", 0, 0, true},
+		{"100x", 0, 0, true},
+		{"", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			w, h, err := ParsePageSize(tt.input)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("ParsePageSize(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePageSize(%q) unexpected error: %v", tt.input, err)
+			}
+			if w != tt.width || h != tt.height {
+				t.Errorf("ParsePageSize(%q) = %vx%v, want %vx%v", tt.input, w, h, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	if p, err := ParsePlacement("top"); err != nil || p != PlaceTop {
+		t.Errorf("ParsePlacement(top) = %v, %v", p, err)
+	}
+	if p, err := ParsePlacement(""); err != nil || p != PlaceCenter {
+		t.Errorf("ParsePlacement(\"\") = %v, %v", p, err)
+	}
+	if _, err := ParsePlacement("left"); err == nil {
+		t.Error("ParsePlacement(left) expected error, got nil")
+	}
+}
+
+func TestToPDF(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	cfg := DefaultPDFConfig()
+	cfg.Caption = "Scan (me)"
+	data, err := ToPDF(qr, cfg)
+	if err != nil {
+		t.Fatalf("ToPDF() error: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) {
+		t.Error("ToPDF() missing PDF header")
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("ToPDF() missing EOF marker")
+	}
+	pdf := string(data)
+	// A4 in points
+	if !strings.Contains(pdf, "/MediaBox [0 0 595.276 841.89]") {
+		t.Error("ToPDF() does not use A4 page size")
+	}
+	if !strings.Contains(pdf, `(Scan \(me\)) Tj`) {
+		t.Error("ToPDF() caption missing or not escaped")
+	}
+	if !strings.Contains(pdf, "/BaseFont /Helvetica") {
+		t.Error("ToPDF() caption font missing")
+	}
+
+	// xref offsets must point at the objects
+	xref := strings.Index(pdf, "\nxref\n") + 1
+	entries := strings.Split(pdf[xref:], "\n")[3:]
+	for i := 1; i <= 5; i++ {
+		off, err := strconv.Atoi(strings.Fields(entries[i-1])[0])
+		if err != nil {
+			t.Fatalf("invalid xref entry %q", entries[i-1])
+		}
+		if !strings.HasPrefix(pdf[off:], fmt.Sprintf("%d 0 obj", i)) {
+			t.Errorf("xref entry %d does not point at object", i)
+		}
+	}
+}
+
+func TestToPDFTooLarge(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	cfg := DefaultPDFConfig()
+	cfg.Width = 500
+	if _, err := ToPDF(qr, cfg); err == nil {
+		t.Error("ToPDF() with oversized code should fail")
+	}
+
+	cfg = DefaultPDFConfig()
+	cfg.Margin = 200
+	if _, err := ToPDF(qr, cfg); err == nil {
+		t.Error("ToPDF() with oversized margins should fail")
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	text, width := pdfText(`a\b`)
+	if text != `a\\b` {
+		t.Errorf("pdfText() = %q, want %q", text, `a\\b`)
+	}
+	if width != 556+278+556 {
+		t.Errorf("pdfText() width = %v, want %v", width, 556+278+556)
+	}
+
+	text, _ = pdfText("é张")
+	if text != `\351?` {
+		t.Errorf("pdfText() = %q, want %q", text, `\351?`)
+	}
+}
+
+func TestSavePDF(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mkqr-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "nested", "test.pdf")
+	if err := SavePDF(qr, filename, DefaultPDFConfig()); err != nil {
+		t.Fatalf("SavePDF() error: %v", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("File not created: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("File is empty")
+	}
+}
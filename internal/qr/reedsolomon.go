@@ -0,0 +1,190 @@
+package qr
+
+import "errors"
+
+// errTooManyErrors is returned when a block holds more errors than its
+// error correction codewords can repair
+var errTooManyErrors = errors.New("too many errors to correct")
+
+// GF(256) arithmetic with the QR code field polynomial
+// x^8 + x^4 + x^3 + x^2 + 1. gfExp is doubled so products of two logs
+// need no reduction.
+var (
+	gfExp [512]byte
+	gfLog [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+255-gfLog[b]]
+}
+
+// gfPow returns alpha raised to the power e, for any integer e
+func gfPow(e int) byte {
+	e %= 255
+	if e < 0 {
+		e += 255
+	}
+	return gfExp[e]
+}
+
+// polyEval evaluates a polynomial stored lowest degree first at x
+func polyEval(p []byte, x byte) byte {
+	var y byte
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ p[i]
+	}
+	return y
+}
+
+// rsCorrect repairs a block in place. block holds data codewords followed
+// by ecLen error correction codewords, first codeword being the highest
+// degree coefficient. It returns the number of corrected codewords.
+func rsCorrect(block []byte, ecLen int) (int, error) {
+	n := len(block)
+
+	// Syndromes S_j = R(alpha^j); all zero means the block is intact
+	syndromes := make([]byte, ecLen)
+	clean := true
+	for j := range syndromes {
+		var s byte
+		x := gfPow(j)
+		for _, c := range block {
+			s = gfMul(s, x) ^ c
+		}
+		syndromes[j] = s
+		if s != 0 {
+			clean = false
+		}
+	}
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp-Massey finds the error locator polynomial
+	locator := []byte{1}
+	prev := []byte{1}
+	errCount, shift := 0, 1
+	var prevDiscrepancy byte = 1
+	for i := 0; i < ecLen; i++ {
+		d := syndromes[i]
+		for j := 1; j <= errCount && j < len(locator); j++ {
+			d ^= gfMul(locator[j], syndromes[i-j])
+		}
+		if d == 0 {
+			shift++
+			continue
+		}
+
+		scale := gfDiv(d, prevDiscrepancy)
+		next := make([]byte, max(len(locator), len(prev)+shift))
+		copy(next, locator)
+		for j, c := range prev {
+			next[j+shift] ^= gfMul(scale, c)
+		}
+
+		if 2*errCount <= i {
+			prev = locator
+			errCount = i + 1 - errCount
+			prevDiscrepancy = d
+			shift = 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+	if 2*errCount > ecLen {
+		return 0, errTooManyErrors
+	}
+
+	// Chien search: codeword i sits at power n-1-i
+	var positions []int
+	for i := 0; i < n; i++ {
+		if polyEval(locator, gfPow(-(n-1-i))) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != errCount {
+		return 0, errTooManyErrors
+	}
+
+	// Forney: evaluator = S(x) * locator(x) mod x^ecLen
+	evaluator := make([]byte, ecLen)
+	for i, s := range syndromes {
+		for j, l := range locator {
+			if i+j < ecLen {
+				evaluator[i+j] ^= gfMul(s, l)
+			}
+		}
+	}
+	derivative := make([]byte, len(locator))
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	for _, pos := range positions {
+		x := gfPow(n - 1 - pos)
+		xInv := gfPow(-(n - 1 - pos))
+		denom := polyEval(derivative, xInv)
+		if denom == 0 {
+			return 0, errTooManyErrors
+		}
+		block[pos] ^= gfMul(x, gfDiv(polyEval(evaluator, xInv), denom))
+	}
+	return len(positions), nil
+}
+
+// rsGenerator returns the generator polynomial of degree ecLen, the
+// product of (x - alpha^i) for i below ecLen, highest degree first
+func rsGenerator(ecLen int) []byte {
+	g := []byte{1}
+	for i := 0; i < ecLen; i++ {
+		next := make([]byte, len(g)+1)
+		for j, c := range g {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfPow(i))
+		}
+		g = next
+	}
+	return g
+}
+
+// rsEncode returns the ecLen error correction codewords of a data block,
+// the remainder of data(x) * x^ecLen divided by the generator polynomial
+func rsEncode(data []byte, ecLen int) []byte {
+	g := rsGenerator(ecLen)
+	rem := make([]byte, ecLen)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[ecLen-1] = 0
+		for j := range rem {
+			rem[j] ^= gfMul(g[j+1], factor)
+		}
+	}
+	return rem
+}
@@ -0,0 +1,221 @@
+package qr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ModuleStyle controls the shape of data modules in image output
+type ModuleStyle string
+
+const (
+	StyleSquare    ModuleStyle = "square"    // Classic square modules
+	StyleDot       ModuleStyle = "dot"       // Circular dots
+	StyleRounded   ModuleStyle = "rounded"   // Squares with rounded corners
+	StyleConnected ModuleStyle = "connected" // Rounded outlines joining neighbouring modules
+)
+
+// ParseModuleStyle parses a string into ModuleStyle
+func ParseModuleStyle(s string) (ModuleStyle, error) {
+	switch strings.ToLower(s) {
+	case "square", "":
+		return StyleSquare, nil
+	case "dot", "dots", "circle":
+		return StyleDot, nil
+	case "rounded", "round":
+		return StyleRounded, nil
+	case "connected", "connected-rounded":
+		return StyleConnected, nil
+	default:
+		return StyleSquare, fmt.Errorf("invalid module style: %s (use square, dot, rounded, or connected)", s)
+	}
+}
+
+// EyeStyle controls the shape of the three finder patterns ("eyes")
+type EyeStyle string
+
+const (
+	EyeSquare  EyeStyle = "square"  // Standard square finder patterns
+	EyeRounded EyeStyle = "rounded" // Rounded frame and centre
+	EyeCircle  EyeStyle = "circle"  // Circular frame and centre
+)
+
+// ParseEyeStyle parses a string into EyeStyle
+func ParseEyeStyle(s string) (EyeStyle, error) {
+	switch strings.ToLower(s) {
+	case "square", "":
+		return EyeSquare, nil
+	case "rounded", "round":
+		return EyeRounded, nil
+	case "circle", "circular":
+		return EyeCircle, nil
+	default:
+		return EyeSquare, fmt.Errorf("invalid eye style: %s (use square, rounded, or circle)", s)
+	}
+}
+
+// styled reports whether the options need the shape renderer instead of
+// plain square modules
+func (o Options) styled() bool {
+	return (o.ModuleStyle != "" && o.ModuleStyle != StyleSquare) ||
+		(o.EyeStyle != "" && o.EyeStyle != EyeSquare)
+}
+
+// roundRect is a rectangle with individually rounded corners, in module
+// units. Radii are ordered top-left, top-right, bottom-right, bottom-left.
+type roundRect struct {
+	x, y, w, h float64
+	r          [4]float64
+}
+
+// contains reports whether the point (px, py) lies inside the rectangle
+func (rr roundRect) contains(px, py float64) bool {
+	if px < rr.x || py < rr.y || px >= rr.x+rr.w || py >= rr.y+rr.h {
+		return false
+	}
+
+	// Outside a corner square, only the rectangle test matters
+	corners := [4][2]float64{
+		{rr.x + rr.r[0], rr.y + rr.r[0]},
+		{rr.x + rr.w - rr.r[1], rr.y + rr.r[1]},
+		{rr.x + rr.w - rr.r[2], rr.y + rr.h - rr.r[2]},
+		{rr.x + rr.r[3], rr.y + rr.h - rr.r[3]},
+	}
+	for i, c := range corners {
+		r := rr.r[i]
+		if r == 0 {
+			continue
+		}
+		inX := (i == 0 || i == 3) && px < c[0] || (i == 1 || i == 2) && px > c[0]
+		inY := (i == 0 || i == 1) && py < c[1] || (i == 2 || i == 3) && py > c[1]
+		if inX && inY {
+			return math.Hypot(px-c[0], py-c[1]) <= r
+		}
+	}
+	return true
+}
+
+// svgPath returns the rectangle outline as SVG path data
+func (rr roundRect) svgPath() string {
+	x, y, w, h, r := rr.x, rr.y, rr.w, rr.h, rr.r
+	var b strings.Builder
+
+	arc := func(r, x, y float64) {
+		if r > 0 {
+			fmt.Fprintf(&b, "A%s %s 0 0 1 %s %s", formatNum(r), formatNum(r), formatNum(x), formatNum(y))
+		}
+	}
+
+	fmt.Fprintf(&b, "M%s %s", formatNum(x+r[0]), formatNum(y))
+	fmt.Fprintf(&b, "H%s", formatNum(x+w-r[1]))
+	arc(r[1], x+w, y+r[1])
+	fmt.Fprintf(&b, "V%s", formatNum(y+h-r[2]))
+	arc(r[2], x+w-r[2], y+h)
+	fmt.Fprintf(&b, "H%s", formatNum(x+r[3]))
+	arc(r[3], x, y+h-r[3])
+	fmt.Fprintf(&b, "V%s", formatNum(y+r[0]))
+	arc(r[0], x+r[0], y)
+	b.WriteString("z")
+
+	return b.String()
+}
+
+// shape is one layer of a styled code. Holes clear what earlier shapes
+// filled, which is how finder frames are built.
+type shape struct {
+	rect roundRect
+	hole bool
+}
+
+// finderOrigins returns the top-left corners of the finder patterns in a
+// bitmap of width dim with a quiet zone of margin modules: three, or only
+// the top-left one of a Micro QR symbol
+func finderOrigins(dim, margin int, micro bool) [][2]int {
+	if micro {
+		return [][2]int{{margin, margin}}
+	}
+	far := dim - margin - 7
+	return [][2]int{{margin, margin}, {far, margin}, {margin, far}}
+}
+
+// inFinder reports whether module (x, y) belongs to a finder pattern
+func inFinder(dim, margin int, micro bool, x, y int) bool {
+	for _, o := range finderOrigins(dim, margin, micro) {
+		if x >= o[0] && x < o[0]+7 && y >= o[1] && y < o[1]+7 {
+			return true
+		}
+	}
+	return false
+}
+
+// styledShapes converts a bitmap into shapes for the module and eye
+// styles in opts. Shapes never overlap except for finder holes, which lie
+// inside their frame.
+func styledShapes(bitmap [][]bool, opts Options) []shape {
+	dim := len(bitmap)
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < dim && y < dim && bitmap[y][x] && !inFinder(dim, opts.Margin, opts.Micro, x, y)
+	}
+
+	var shapes []shape
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if !dark(x, y) {
+				continue
+			}
+			fx, fy := float64(x), float64(y)
+
+			switch opts.ModuleStyle {
+			case StyleDot:
+				shapes = append(shapes, shape{rect: roundRect{fx + 0.05, fy + 0.05, 0.9, 0.9, [4]float64{0.45, 0.45, 0.45, 0.45}}})
+			case StyleRounded:
+				shapes = append(shapes, shape{rect: roundRect{fx, fy, 1, 1, [4]float64{0.3, 0.3, 0.3, 0.3}}})
+			case StyleConnected:
+				// Round only the corners that have no neighbour on either side
+				up, down := dark(x, y-1), dark(x, y+1)
+				left, right := dark(x-1, y), dark(x+1, y)
+				var r [4]float64
+				if !up && !left {
+					r[0] = 0.5
+				}
+				if !up && !right {
+					r[1] = 0.5
+				}
+				if !down && !right {
+					r[2] = 0.5
+				}
+				if !down && !left {
+					r[3] = 0.5
+				}
+				shapes = append(shapes, shape{rect: roundRect{fx, fy, 1, 1, r}})
+			default:
+				shapes = append(shapes, shape{rect: roundRect{fx, fy, 1, 1, [4]float64{}}})
+			}
+		}
+	}
+
+	// Finder patterns: a 7x7 frame with a 5x5 hole and a 3x3 centre
+	var outer, inner, ball float64
+	switch opts.EyeStyle {
+	case EyeRounded:
+		outer, inner, ball = 2, 1.5, 1
+	case EyeCircle:
+		outer, inner, ball = 3.5, 2.5, 1.5
+	}
+	for _, o := range finderOrigins(dim, opts.Margin, opts.Micro) {
+		// The logo never reaches the finders, but check the bitmap anyway
+		// so a cleared finder stays cleared
+		if !bitmap[o[1]][o[0]] {
+			continue
+		}
+		fx, fy := float64(o[0]), float64(o[1])
+		shapes = append(shapes,
+			shape{rect: roundRect{fx, fy, 7, 7, [4]float64{outer, outer, outer, outer}}},
+			shape{rect: roundRect{fx + 1, fy + 1, 5, 5, [4]float64{inner, inner, inner, inner}}, hole: true},
+			shape{rect: roundRect{fx + 2, fy + 2, 3, 3, [4]float64{ball, ball, ball, ball}}},
+		)
+	}
+
+	return shapes
+}
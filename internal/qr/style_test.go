@@ -0,0 +1,188 @@
+package qr
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseModuleStyle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ModuleStyle
+		hasError bool
+	}{
+		{"square", StyleSquare, false},
+		{"", StyleSquare, false},
+		{"dot", StyleDot, false},
+		{"DOTS", StyleDot, false},
+		{"rounded", StyleRounded, false},
+		{"connected", StyleConnected, false},
+		{"connected-rounded", StyleConnected, false},
+		{"star", StyleSquare, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseModuleStyle(tt.input)
+			if tt.hasError != (err != nil) {
+				t.Fatalf("ParseModuleStyle(%q) error = %v, want error %v", tt.input, err, tt.hasError)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseModuleStyle(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseEyeStyle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected EyeStyle
+		hasError bool
+	}{
+		{"square", EyeSquare, false},
+		{"", EyeSquare, false},
+		{"rounded", EyeRounded, false},
+		{"Circle", EyeCircle, false},
+		{"diamond", EyeSquare, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseEyeStyle(tt.input)
+			if tt.hasError != (err != nil) {
+				t.Fatalf("ParseEyeStyle(%q) error = %v, want error %v", tt.input, err, tt.hasError)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseEyeStyle(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoundRectContains(t *testing.T) {
+	circle := roundRect{0, 0, 1, 1, [4]float64{0.5, 0.5, 0.5, 0.5}}
+	if !circle.contains(0.5, 0.5) {
+		t.Error("circle should contain its centre")
+	}
+	if circle.contains(0.05, 0.05) {
+		t.Error("circle should not contain its corner")
+	}
+
+	// Only the top-left corner is rounded
+	rr := roundRect{0, 0, 1, 1, [4]float64{0.5, 0, 0, 0}}
+	if rr.contains(0.05, 0.05) {
+		t.Error("rounded corner should be cut off")
+	}
+	if !rr.contains(0.95, 0.05) || !rr.contains(0.95, 0.95) || !rr.contains(0.05, 0.95) {
+		t.Error("square corners should be kept")
+	}
+	if rr.contains(1.5, 0.5) {
+		t.Error("point outside the bounds should not be contained")
+	}
+}
+
+func TestRoundRectSVGPath(t *testing.T) {
+	square := roundRect{1, 2, 3, 4, [4]float64{}}
+	if path := square.svgPath(); path != "M1 2H4V6H1V2z" {
+		t.Errorf("svgPath() = %q, want %q", path, "M1 2H4V6H1V2z")
+	}
+
+	rounded := roundRect{0, 0, 2, 2, [4]float64{1, 1, 1, 1}}
+	if path := rounded.svgPath(); strings.Count(path, "A") != 4 {
+		t.Errorf("svgPath() expected 4 arcs, got %q", path)
+	}
+}
+
+func TestStyledShapes(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	bitmap := qr.Bitmap()
+
+	dark := 0
+	for y, row := range bitmap {
+		for x, v := range row {
+			if v && !inFinder(len(bitmap), DefaultMargin, false, x, y) {
+				dark++
+			}
+		}
+	}
+
+	shapes := styledShapes(bitmap, Options{Margin: DefaultMargin, ModuleStyle: StyleDot, EyeStyle: EyeCircle})
+	// One shape per data module plus frame, hole and centre per finder
+	if len(shapes) != dark+9 {
+		t.Errorf("styledShapes() returned %d shapes, want %d", len(shapes), dark+9)
+	}
+	holes := 0
+	for _, s := range shapes {
+		if s.hole {
+			holes++
+		}
+	}
+	if holes != 3 {
+		t.Errorf("styledShapes() returned %d holes, want 3", holes)
+	}
+}
+
+func TestConnectedCorners(t *testing.T) {
+	// A horizontal pair of modules away from the finders
+	dim := 21 + 2*DefaultMargin
+	bitmap := make([][]bool, dim)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, dim)
+	}
+	bitmap[14][14], bitmap[14][15] = true, true
+
+	shapes := styledShapes(bitmap, Options{Margin: DefaultMargin, ModuleStyle: StyleConnected, EyeStyle: EyeSquare})
+	if len(shapes) != 2 {
+		t.Fatalf("styledShapes() returned %d shapes, want 2", len(shapes))
+	}
+	left, right := shapes[0].rect.r, shapes[1].rect.r
+	if left != [4]float64{0.5, 0, 0, 0.5} {
+		t.Errorf("left module radii = %v, want rounded on the left only", left)
+	}
+	if right != [4]float64{0, 0.5, 0.5, 0} {
+		t.Errorf("right module radii = %v, want rounded on the right only", right)
+	}
+}
+
+func TestRenderStyled(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ModuleStyle = StyleDot
+	opts.EyeStyle = EyeCircle
+	qr, err := NewGenerator(opts).Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	dim := len(qr.Bitmap())
+	size := dim * 10
+	img := renderImage(qr, size)
+
+	// Circular eyes leave the corner of the finder frame empty, while the
+	// middle of the frame's top edge is dark
+	corner := DefaultMargin*10 + 1
+	if c := color.GrayModel.Convert(img.At(corner, corner)).(color.Gray); c.Y < 0xf0 {
+		t.Errorf("circle eye corner = %d, want background", c.Y)
+	}
+	edge := DefaultMargin*10 + 35
+	if c := color.GrayModel.Convert(img.At(edge, corner+4)).(color.Gray); c.Y > 0x10 {
+		t.Errorf("circle eye edge = %d, want foreground", c.Y)
+	}
+
+	svg, err := ToSVG(qr, 256)
+	if err != nil {
+		t.Fatalf("ToSVG() error: %v", err)
+	}
+	if !strings.Contains(string(svg), `fill-rule="evenodd"`) {
+		t.Error("ToSVG() styled output should use evenodd fill")
+	}
+
+	if _, err := ToPDF(qr, DefaultPDFConfig()); err == nil {
+		t.Error("ToPDF() with styles should fail")
+	}
+}
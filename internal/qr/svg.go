@@ -0,0 +1,129 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+)
+
+// WriteSVG writes the QR code as an SVG document to w.
+// Dark modules on each row are merged into horizontal runs, so the
+// document contains a single path instead of one rect per module.
+// Styled modules are emitted as individual subpaths of that path.
+func WriteSVG(w io.Writer, qr *Code, size int) error {
+	bitmap := qr.Bitmap()
+	dim := len(bitmap)
+	size = qr.PixelSize(size)
+
+	var logo string
+	if qr.opts.Logo != nil {
+		var err error
+		if logo, err = svgLogo(qr, dim); err != nil {
+			return err
+		}
+		bitmap = clearLogoArea(bitmap, qr.opts)
+	}
+
+	fg := qr.ForegroundColor
+	if fg == nil {
+		fg = color.Black
+	}
+	bg := qr.BackgroundColor
+	if bg == nil {
+		bg = color.White
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	// Square modules stay sharp; curved styles need anti-aliasing
+	rendering := "crispEdges"
+	if qr.opts.styled() {
+		rendering = "geometricPrecision"
+	}
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="%s">`+"\n",
+		size, size, dim, dim, rendering)
+
+	// The bitmap already contains the quiet zone, so the background
+	// covers the whole viewBox
+	if _, _, _, a := bg.RGBA(); a != 0 {
+		fmt.Fprintf(&b, `<rect width="%d" height="%d"%s/>`+"\n", dim, dim, svgFill(bg))
+	}
+
+	if qr.opts.styled() {
+		var d strings.Builder
+		for _, s := range styledShapes(bitmap, qr.opts) {
+			d.WriteString(s.rect.svgPath())
+		}
+		// Shapes only overlap where finder holes cut into their frames
+		fmt.Fprintf(&b, `<path d="%s" fill-rule="evenodd"%s/>`+"\n", d.String(), svgFill(fg))
+	} else {
+		fmt.Fprintf(&b, `<path d="%s"%s/>`+"\n", svgPath(bitmap), svgFill(fg))
+	}
+	b.WriteString(logo)
+	b.WriteString("</svg>\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write SVG: %w", err)
+	}
+	return nil
+}
+
+// ToSVG returns the QR code as an SVG document
+func ToSVG(qr *Code, size int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, qr, size); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SaveSVG saves the QR code as an SVG file
+func SaveSVG(qr *Code, filename string, size int) error {
+	data, err := ToSVG(qr, size)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureDir(filename); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SVG file: %w", err)
+	}
+	return nil
+}
+
+// svgPath builds path data covering every dark module, one subpath per
+// horizontal run
+func svgPath(bitmap [][]bool) string {
+	var b strings.Builder
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	return b.String()
+}
+
+// svgFill returns fill attributes for c, including opacity when c is
+// not fully opaque
+func svgFill(c color.Color) string {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	attr := fmt.Sprintf(` fill="#%02x%02x%02x"`, nrgba.R, nrgba.G, nrgba.B)
+	if nrgba.A != 0xff {
+		attr += fmt.Sprintf(` fill-opacity="%.3f"`, float64(nrgba.A)/0xff)
+	}
+	return attr
+}
@@ -0,0 +1,109 @@
+package qr
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToSVG(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	data, err := ToSVG(qr, 256)
+	if err != nil {
+		t.Fatalf("ToSVG() error: %v", err)
+	}
+	svg := string(data)
+
+	if !strings.HasPrefix(svg, "<?xml") {
+		t.Error("ToSVG() missing XML declaration")
+	}
+	if !strings.Contains(svg, `width="256" height="256"`) {
+		t.Error("ToSVG() does not honor size")
+	}
+
+	dim := len(qr.Bitmap())
+	if !strings.Contains(svg, fmt.Sprintf(`viewBox="0 0 %d %d"`, dim, dim)) {
+		t.Errorf("ToSVG() viewBox does not cover %d modules including quiet zone", dim)
+	}
+
+	// Modules are merged into a single path, not one rect per module
+	if strings.Count(svg, "<path") != 1 {
+		t.Errorf("ToSVG() expected exactly one path, got %d", strings.Count(svg, "<path"))
+	}
+	if strings.Count(svg, "<rect") != 1 {
+		t.Errorf("ToSVG() expected only the background rect, got %d", strings.Count(svg, "<rect"))
+	}
+	if !strings.Contains(svg, `fill="#000000"`) || !strings.Contains(svg, `fill="#ffffff"`) {
+		t.Error("ToSVG() missing default colors")
+	}
+}
+
+func TestToSVGColors(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ForegroundColor = color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+	opts.BackgroundColor = color.Transparent
+	qr, err := NewGenerator(opts).Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	data, err := ToSVG(qr, 256)
+	if err != nil {
+		t.Fatalf("ToSVG() error: %v", err)
+	}
+	svg := string(data)
+
+	if !strings.Contains(svg, `fill="#123456"`) {
+		t.Error("ToSVG() does not honor foreground color")
+	}
+	if strings.Contains(svg, "<rect") {
+		t.Error("ToSVG() should omit background for transparent color")
+	}
+}
+
+func TestSVGPath(t *testing.T) {
+	bitmap := [][]bool{
+		{true, true, false, true},
+		{false, false, false, false},
+	}
+
+	expected := "M0 0h2v1h-2zM3 0h1v1h-1z"
+	if result := svgPath(bitmap); result != expected {
+		t.Errorf("svgPath() = %q, want %q", result, expected)
+	}
+}
+
+func TestSaveSVG(t *testing.T) {
+	gen := NewGenerator(DefaultOptions())
+	qr, err := gen.Generate("Test content")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mkqr-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "nested", "test.svg")
+	if err := SaveSVG(qr, filename, 256); err != nil {
+		t.Fatalf("SaveSVG() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("File not created: %v", err)
+	}
+	if !strings.Contains(string(data), "<svg") {
+		t.Error("Saved file is not an SVG document")
+	}
+}
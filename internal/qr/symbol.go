@@ -0,0 +1,251 @@
+package qr
+
+// AutoMask selects the mask pattern with the lowest penalty score
+const AutoMask = -1
+
+// buildSymbol places the function patterns and codewords in a symbol and
+// applies mask, or with AutoMask each of the eight masks in turn keeping
+// the one with the lowest penalty. It returns the symbol and the mask.
+func buildSymbol(codewords []byte, version int, level ErrorCorrectionLevel, mask int) ([][]bool, int) {
+	dim := symbolSize(version)
+	fn := functionPattern(version)
+	base := newGrid(dim)
+	drawFunctionPatterns(base, version)
+	placeCodewords(base, fn, codewords)
+
+	if mask != AutoMask {
+		return applyMask(base, fn, level, mask), mask
+	}
+
+	var best [][]bool
+	bestMask, bestPenalty := 0, -1
+	for m := 0; m < 8; m++ {
+		symbol := applyMask(base, fn, level, m)
+		if p := penalty(symbol); bestPenalty < 0 || p < bestPenalty {
+			best, bestMask, bestPenalty = symbol, m, p
+		}
+	}
+	return best, bestMask
+}
+
+func newGrid(dim int) [][]bool {
+	grid := make([][]bool, dim)
+	for i := range grid {
+		grid[i] = make([]bool, dim)
+	}
+	return grid
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns,
+// the dark module and the version information. Format information depends
+// on the mask and is drawn by applyMask.
+func drawFunctionPatterns(m [][]bool, version int) {
+	dim := len(m)
+
+	// Finder patterns: a dark 7x7 ring around a dark 3x3 square
+	for _, corner := range [][2]int{{0, 0}, {dim - 7, 0}, {0, dim - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				m[corner[1]+dy][corner[0]+dx] = ring != 2
+			}
+		}
+	}
+
+	// Timing patterns
+	for i := 8; i < dim-8; i++ {
+		m[6][i] = i%2 == 0
+		m[i][6] = i%2 == 0
+	}
+
+	// Alignment patterns: a dark 5x5 ring around a dark centre
+	centres := versions[version-1].alignment
+	last := len(centres) - 1
+	for i, cy := range centres {
+		for j, cx := range centres {
+			if (i == 0 && (j == 0 || j == last)) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					m[cy+dy][cx+dx] = max(abs(dx), abs(dy)) != 1
+				}
+			}
+		}
+	}
+
+	m[dim-8][8] = true
+
+	if version >= 7 {
+		v := versionBits(version)
+		i := 17
+		for y := 5; y >= 0; y-- {
+			for x := dim - 9; x >= dim-11; x-- {
+				m[y][x] = v>>i&1 == 1
+				m[x][y] = v>>i&1 == 1
+				i--
+			}
+		}
+	}
+}
+
+// placeCodewords fills the data modules in the zigzag order read by
+// readCodewords. Remainder modules past the last codeword stay light.
+func placeCodewords(m, fn [][]bool, codewords []byte) {
+	bit := 0
+	zigzag(fn, func(y, x int) {
+		if bit < 8*len(codewords) {
+			m[y][x] = codewords[bit/8]>>(7-bit%8)&1 == 1
+		}
+		bit++
+	})
+}
+
+// zigzag visits the data modules, those not marked in fn, in placement
+// order: up and down two-module columns from the bottom right, skipping
+// the vertical timing pattern of QR symbols in column 6
+func zigzag(fn [][]bool, visit func(y, x int)) {
+	dim := len(fn)
+	up := true
+	for right := dim - 1; right > 0; right -= 2 {
+		if right == 6 && dim >= 21 {
+			right--
+		}
+		for i := 0; i < dim; i++ {
+			y := i
+			if up {
+				y = dim - 1 - i
+			}
+			for x := right; x > right-2; x-- {
+				if !fn[y][x] {
+					visit(y, x)
+				}
+			}
+		}
+		up = !up
+	}
+}
+
+// applyMask returns a copy of m with the data modules masked and the
+// format information for level and mask drawn
+func applyMask(m, fn [][]bool, level ErrorCorrectionLevel, mask int) [][]bool {
+	dim := len(m)
+	symbol := newGrid(dim)
+	for y := range m {
+		for x := range m[y] {
+			symbol[y][x] = m[y][x]
+			if !fn[y][x] && maskBit(mask, y, x) {
+				symbol[y][x] = !symbol[y][x]
+			}
+		}
+	}
+
+	// The two copies in the positions, most significant bit first, that
+	// readFormat reads them from
+	format := formatBits(level, mask)
+	var first, second [][2]int
+	for x := 0; x <= 5; x++ {
+		first = append(first, [2]int{x, 8})
+	}
+	first = append(first, [2]int{7, 8}, [2]int{8, 8}, [2]int{8, 7})
+	for y := 5; y >= 0; y-- {
+		first = append(first, [2]int{8, y})
+	}
+	for y := dim - 1; y >= dim-7; y-- {
+		second = append(second, [2]int{8, y})
+	}
+	for x := dim - 8; x < dim; x++ {
+		second = append(second, [2]int{x, 8})
+	}
+	for i := 0; i < 15; i++ {
+		dark := format>>(14-i)&1 == 1
+		symbol[first[i][1]][first[i][0]] = dark
+		symbol[second[i][1]][second[i][0]] = dark
+	}
+	return symbol
+}
+
+// penalty scores a masked symbol by the four rules of ISO/IEC 18004
+// section 7.8.3; lower scores are easier to scan
+func penalty(m [][]bool) int {
+	dim := len(m)
+	score := 0
+
+	// Rule 1: runs of five or more same coloured modules in a line
+	// Rule 3: finder-like 1:1:3:1:1 patterns with four light modules on
+	// either side
+	for i := 0; i < dim; i++ {
+		row := m[i]
+		col := make([]bool, dim)
+		for j := range col {
+			col[j] = m[j][i]
+		}
+		for _, line := range [][]bool{row, col} {
+			run := 1
+			for j := 1; j <= dim; j++ {
+				if j < dim && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			score += 40 * finderLikePatterns(line)
+		}
+	}
+
+	// Rule 2: 2x2 blocks of one colour
+	for y := 0; y < dim-1; y++ {
+		for x := 0; x < dim-1; x++ {
+			c := m[y][x]
+			if m[y][x+1] == c && m[y+1][x] == c && m[y+1][x+1] == c {
+				score += 3
+			}
+		}
+	}
+
+	// Rule 4: deviation of the dark proportion from 50%, in steps of 5%
+	dark := 0
+	for _, row := range m {
+		for _, c := range row {
+			if c {
+				dark++
+			}
+		}
+	}
+	percent := dark * 100 / (dim * dim)
+	score += 10 * (abs(percent-50) / 5)
+	return score
+}
+
+// finderLikePatterns counts dark-light-dark-dark-dark-light-dark runs in
+// line preceded or followed by four light modules. Modules beyond the
+// symbol count as light, as the quiet zone is.
+func finderLikePatterns(line []bool) int {
+	pattern := [7]bool{true, false, true, true, true, false, true}
+	light := func(from, to int) bool {
+		for i := max(from, 0); i < min(to, len(line)); i++ {
+			if line[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	count := 0
+	for i := 0; i+7 <= len(line); i++ {
+		match := true
+		for j, c := range pattern {
+			if line[i+j] != c {
+				match = false
+				break
+			}
+		}
+		if match && (light(i-4, i) || light(i+7, i+11)) {
+			count++
+		}
+	}
+	return count
+}
@@ -3,9 +3,6 @@ package qr
 import (
 	"fmt"
 	"io"
-	"strings"
-
-	"github.com/skip2/go-qrcode"
 )
 
 // TerminalConfig configures terminal output
@@ -15,7 +12,7 @@ type TerminalConfig struct {
 }
 
 // RenderTerminal renders a QR code to the terminal
-func RenderTerminal(w io.Writer, qr *qrcode.QRCode, cfg TerminalConfig) {
+func RenderTerminal(w io.Writer, qr *Code, cfg TerminalConfig) {
 	bitmap := qr.Bitmap()
 
 	if cfg.Small {
@@ -25,7 +22,8 @@ func RenderTerminal(w io.Writer, qr *qrcode.QRCode, cfg TerminalConfig) {
 	}
 }
 
-// renderNormal renders using full block characters (2x2 pixels per character)
+// renderNormal renders using full block characters (2x2 pixels per character).
+// The bitmap is expected to include its quiet zone.
 func renderNormal(w io.Writer, bitmap [][]bool, invert bool) {
 	white := "██"
 	black := "  "
@@ -33,16 +31,7 @@ func renderNormal(w io.Writer, bitmap [][]bool, invert bool) {
 		white, black = black, white
 	}
 
-	// Add quiet zone
-	width := len(bitmap[0])
-	quietLine := strings.Repeat(white, width+4)
-
-	// Top quiet zone
-	fmt.Fprintln(w, quietLine)
-	fmt.Fprintln(w, quietLine)
-
 	for _, row := range bitmap {
-		fmt.Fprint(w, white+white) // Left quiet zone
 		for _, cell := range row {
 			if cell {
 				fmt.Fprint(w, black)
@@ -50,43 +39,26 @@ func renderNormal(w io.Writer, bitmap [][]bool, invert bool) {
 				fmt.Fprint(w, white)
 			}
 		}
-		fmt.Fprintln(w, white+white) // Right quiet zone
+		fmt.Fprintln(w)
 	}
-
-	// Bottom quiet zone
-	fmt.Fprintln(w, quietLine)
-	fmt.Fprintln(w, quietLine)
 }
 
-// renderSmall renders using half-block characters (2 rows per line)
+// renderSmall renders using half-block characters (2 rows per line).
+// The bitmap is expected to include its quiet zone.
 func renderSmall(w io.Writer, bitmap [][]bool, invert bool) {
 	// Unicode half blocks: ▀ (upper), ▄ (lower), █ (full), " " (empty)
 	const (
-		upperHalf = "▀"
-		lowerHalf = "▄"
-		fullBlock = "█"
+		upperHalf  = "▀"
+		lowerHalf  = "▄"
+		fullBlock  = "█"
 		emptyBlock = " "
 	)
 
 	height := len(bitmap)
 	width := len(bitmap[0])
 
-	// Quiet zone width
-	qz := 2
-
-	// Top quiet zone (represented as full blocks in inverted sense)
-	quietChar := fullBlock
-	if invert {
-		quietChar = emptyBlock
-	}
-	quietLine := strings.Repeat(quietChar, width+qz*2)
-	fmt.Fprintln(w, quietLine)
-
 	// Process two rows at a time
 	for y := 0; y < height; y += 2 {
-		// Left quiet zone
-		fmt.Fprint(w, strings.Repeat(quietChar, qz))
-
 		for x := 0; x < width; x++ {
 			upper := bitmap[y][x]
 			lower := false
@@ -127,11 +99,6 @@ func renderSmall(w io.Writer, bitmap [][]bool, invert bool) {
 			}
 			fmt.Fprint(w, char)
 		}
-
-		// Right quiet zone
-		fmt.Fprintln(w, strings.Repeat(quietChar, qz))
+		fmt.Fprintln(w)
 	}
-
-	// Bottom quiet zone
-	fmt.Fprintln(w, quietLine)
 }
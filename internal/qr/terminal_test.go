@@ -122,3 +122,31 @@ func TestRenderSmallOddRows(t *testing.T) {
 		t.Error("renderSmall() with odd rows produced empty output")
 	}
 }
+
+func TestRenderTerminalMargin(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Margin = 1
+	qr, err := NewGenerator(opts).Generate("Test")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	dim := len(qr.Bitmap())
+
+	var buf bytes.Buffer
+	RenderTerminal(&buf, qr, TerminalConfig{})
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != dim {
+		t.Errorf("RenderTerminal() produced %d lines, want %d", len(lines), dim)
+	}
+	// One quiet zone module on each side, two characters per module
+	if width := len([]rune(lines[0])); width != dim*2 {
+		t.Errorf("RenderTerminal() line width = %d, want %d", width, dim*2)
+	}
+
+	buf.Reset()
+	RenderTerminal(&buf, qr, TerminalConfig{Small: true})
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != (dim+1)/2 {
+		t.Errorf("RenderTerminal() small produced %d lines, want %d", len(lines), (dim+1)/2)
+	}
+}
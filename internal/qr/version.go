@@ -0,0 +1,191 @@
+package qr
+
+import "math/bits"
+
+// ecBlocks describes the error correction layout of one version at one
+// level: every block has ecPerBlock EC codewords, and the data codewords
+// are split into groups of equally sized blocks
+type ecBlocks struct {
+	ecPerBlock int
+	groups     []ecGroup
+}
+
+// ecGroup is a run of count blocks holding data codewords each
+type ecGroup struct {
+	count int
+	data  int
+}
+
+// blocks returns the total number of blocks
+func (e ecBlocks) blocks() int {
+	n := 0
+	for _, g := range e.groups {
+		n += g.count
+	}
+	return n
+}
+
+// dataCodewords returns the total number of data codewords
+func (e ecBlocks) dataCodewords() int {
+	n := 0
+	for _, g := range e.groups {
+		n += g.count * g.data
+	}
+	return n
+}
+
+// versionInfo holds the symbol parameters of one QR code version
+type versionInfo struct {
+	alignment []int       // Row/column centres of the alignment patterns
+	ec        [4]ecBlocks // Indexed by ErrorCorrectionLevel
+}
+
+// versions holds ISO/IEC 18004 table 9 for versions 1 to 40, indexed by
+// version-1. Levels are in L, M, Q, H order.
+var versions = [40]versionInfo{
+	{nil, [4]ecBlocks{{7, []ecGroup{{1, 19}}}, {10, []ecGroup{{1, 16}}}, {13, []ecGroup{{1, 13}}}, {17, []ecGroup{{1, 9}}}}},
+	{[]int{6, 18}, [4]ecBlocks{{10, []ecGroup{{1, 34}}}, {16, []ecGroup{{1, 28}}}, {22, []ecGroup{{1, 22}}}, {28, []ecGroup{{1, 16}}}}},
+	{[]int{6, 22}, [4]ecBlocks{{15, []ecGroup{{1, 55}}}, {26, []ecGroup{{1, 44}}}, {18, []ecGroup{{2, 17}}}, {22, []ecGroup{{2, 13}}}}},
+	{[]int{6, 26}, [4]ecBlocks{{20, []ecGroup{{1, 80}}}, {18, []ecGroup{{2, 32}}}, {26, []ecGroup{{2, 24}}}, {16, []ecGroup{{4, 9}}}}},
+	{[]int{6, 30}, [4]ecBlocks{{26, []ecGroup{{1, 108}}}, {24, []ecGroup{{2, 43}}}, {18, []ecGroup{{2, 15}, {2, 16}}}, {22, []ecGroup{{2, 11}, {2, 12}}}}},
+	{[]int{6, 34}, [4]ecBlocks{{18, []ecGroup{{2, 68}}}, {16, []ecGroup{{4, 27}}}, {24, []ecGroup{{4, 19}}}, {28, []ecGroup{{4, 15}}}}},
+	{[]int{6, 22, 38}, [4]ecBlocks{{20, []ecGroup{{2, 78}}}, {18, []ecGroup{{4, 31}}}, {18, []ecGroup{{2, 14}, {4, 15}}}, {26, []ecGroup{{4, 13}, {1, 14}}}}},
+	{[]int{6, 24, 42}, [4]ecBlocks{{24, []ecGroup{{2, 97}}}, {22, []ecGroup{{2, 38}, {2, 39}}}, {22, []ecGroup{{4, 18}, {2, 19}}}, {26, []ecGroup{{4, 14}, {2, 15}}}}},
+	{[]int{6, 26, 46}, [4]ecBlocks{{30, []ecGroup{{2, 116}}}, {22, []ecGroup{{3, 36}, {2, 37}}}, {20, []ecGroup{{4, 16}, {4, 17}}}, {24, []ecGroup{{4, 12}, {4, 13}}}}},
+	{[]int{6, 28, 50}, [4]ecBlocks{{18, []ecGroup{{2, 68}, {2, 69}}}, {26, []ecGroup{{4, 43}, {1, 44}}}, {24, []ecGroup{{6, 19}, {2, 20}}}, {28, []ecGroup{{6, 15}, {2, 16}}}}},
+	{[]int{6, 30, 54}, [4]ecBlocks{{20, []ecGroup{{4, 81}}}, {30, []ecGroup{{1, 50}, {4, 51}}}, {28, []ecGroup{{4, 22}, {4, 23}}}, {24, []ecGroup{{3, 12}, {8, 13}}}}},
+	{[]int{6, 32, 58}, [4]ecBlocks{{24, []ecGroup{{2, 92}, {2, 93}}}, {22, []ecGroup{{6, 36}, {2, 37}}}, {26, []ecGroup{{4, 20}, {6, 21}}}, {28, []ecGroup{{7, 14}, {4, 15}}}}},
+	{[]int{6, 34, 62}, [4]ecBlocks{{26, []ecGroup{{4, 107}}}, {22, []ecGroup{{8, 37}, {1, 38}}}, {24, []ecGroup{{8, 20}, {4, 21}}}, {22, []ecGroup{{12, 11}, {4, 12}}}}},
+	{[]int{6, 26, 46, 66}, [4]ecBlocks{{30, []ecGroup{{3, 115}, {1, 116}}}, {24, []ecGroup{{4, 40}, {5, 41}}}, {20, []ecGroup{{11, 16}, {5, 17}}}, {24, []ecGroup{{11, 12}, {5, 13}}}}},
+	{[]int{6, 26, 48, 70}, [4]ecBlocks{{22, []ecGroup{{5, 87}, {1, 88}}}, {24, []ecGroup{{5, 41}, {5, 42}}}, {30, []ecGroup{{5, 24}, {7, 25}}}, {24, []ecGroup{{11, 12}, {7, 13}}}}},
+	{[]int{6, 26, 50, 74}, [4]ecBlocks{{24, []ecGroup{{5, 98}, {1, 99}}}, {28, []ecGroup{{7, 45}, {3, 46}}}, {24, []ecGroup{{15, 19}, {2, 20}}}, {30, []ecGroup{{3, 15}, {13, 16}}}}},
+	{[]int{6, 30, 54, 78}, [4]ecBlocks{{28, []ecGroup{{1, 107}, {5, 108}}}, {28, []ecGroup{{10, 46}, {1, 47}}}, {28, []ecGroup{{1, 22}, {15, 23}}}, {28, []ecGroup{{2, 14}, {17, 15}}}}},
+	{[]int{6, 30, 56, 82}, [4]ecBlocks{{30, []ecGroup{{5, 120}, {1, 121}}}, {26, []ecGroup{{9, 43}, {4, 44}}}, {28, []ecGroup{{17, 22}, {1, 23}}}, {28, []ecGroup{{2, 14}, {19, 15}}}}},
+	{[]int{6, 30, 58, 86}, [4]ecBlocks{{28, []ecGroup{{3, 113}, {4, 114}}}, {26, []ecGroup{{3, 44}, {11, 45}}}, {26, []ecGroup{{17, 21}, {4, 22}}}, {26, []ecGroup{{9, 13}, {16, 14}}}}},
+	{[]int{6, 34, 62, 90}, [4]ecBlocks{{28, []ecGroup{{3, 107}, {5, 108}}}, {26, []ecGroup{{3, 41}, {13, 42}}}, {30, []ecGroup{{15, 24}, {5, 25}}}, {28, []ecGroup{{15, 15}, {10, 16}}}}},
+	{[]int{6, 28, 50, 72, 94}, [4]ecBlocks{{28, []ecGroup{{4, 116}, {4, 117}}}, {26, []ecGroup{{17, 42}}}, {28, []ecGroup{{17, 22}, {6, 23}}}, {30, []ecGroup{{19, 16}, {6, 17}}}}},
+	{[]int{6, 26, 50, 74, 98}, [4]ecBlocks{{28, []ecGroup{{2, 111}, {7, 112}}}, {28, []ecGroup{{17, 46}}}, {30, []ecGroup{{7, 24}, {16, 25}}}, {24, []ecGroup{{34, 13}}}}},
+	{[]int{6, 30, 54, 78, 102}, [4]ecBlocks{{30, []ecGroup{{4, 121}, {5, 122}}}, {28, []ecGroup{{4, 47}, {14, 48}}}, {30, []ecGroup{{11, 24}, {14, 25}}}, {30, []ecGroup{{16, 15}, {14, 16}}}}},
+	{[]int{6, 28, 54, 80, 106}, [4]ecBlocks{{30, []ecGroup{{6, 117}, {4, 118}}}, {28, []ecGroup{{6, 45}, {14, 46}}}, {30, []ecGroup{{11, 24}, {16, 25}}}, {30, []ecGroup{{30, 16}, {2, 17}}}}},
+	{[]int{6, 32, 58, 84, 110}, [4]ecBlocks{{26, []ecGroup{{8, 106}, {4, 107}}}, {28, []ecGroup{{8, 47}, {13, 48}}}, {30, []ecGroup{{7, 24}, {22, 25}}}, {30, []ecGroup{{22, 15}, {13, 16}}}}},
+	{[]int{6, 30, 58, 86, 114}, [4]ecBlocks{{28, []ecGroup{{10, 114}, {2, 115}}}, {28, []ecGroup{{19, 46}, {4, 47}}}, {28, []ecGroup{{28, 22}, {6, 23}}}, {30, []ecGroup{{33, 16}, {4, 17}}}}},
+	{[]int{6, 34, 62, 90, 118}, [4]ecBlocks{{30, []ecGroup{{8, 122}, {4, 123}}}, {28, []ecGroup{{22, 45}, {3, 46}}}, {30, []ecGroup{{8, 23}, {26, 24}}}, {30, []ecGroup{{12, 15}, {28, 16}}}}},
+	{[]int{6, 26, 50, 74, 98, 122}, [4]ecBlocks{{30, []ecGroup{{3, 117}, {10, 118}}}, {28, []ecGroup{{3, 45}, {23, 46}}}, {30, []ecGroup{{4, 24}, {31, 25}}}, {30, []ecGroup{{11, 15}, {31, 16}}}}},
+	{[]int{6, 30, 54, 78, 102, 126}, [4]ecBlocks{{30, []ecGroup{{7, 116}, {7, 117}}}, {28, []ecGroup{{21, 45}, {7, 46}}}, {30, []ecGroup{{1, 23}, {37, 24}}}, {30, []ecGroup{{19, 15}, {26, 16}}}}},
+	{[]int{6, 26, 52, 78, 104, 130}, [4]ecBlocks{{30, []ecGroup{{5, 115}, {10, 116}}}, {28, []ecGroup{{19, 47}, {10, 48}}}, {30, []ecGroup{{15, 24}, {25, 25}}}, {30, []ecGroup{{23, 15}, {25, 16}}}}},
+	{[]int{6, 30, 56, 82, 108, 134}, [4]ecBlocks{{30, []ecGroup{{13, 115}, {3, 116}}}, {28, []ecGroup{{2, 46}, {29, 47}}}, {30, []ecGroup{{42, 24}, {1, 25}}}, {30, []ecGroup{{23, 15}, {28, 16}}}}},
+	{[]int{6, 34, 60, 86, 112, 138}, [4]ecBlocks{{30, []ecGroup{{17, 115}}}, {28, []ecGroup{{10, 46}, {23, 47}}}, {30, []ecGroup{{10, 24}, {35, 25}}}, {30, []ecGroup{{19, 15}, {35, 16}}}}},
+	{[]int{6, 30, 58, 86, 114, 142}, [4]ecBlocks{{30, []ecGroup{{17, 115}, {1, 116}}}, {28, []ecGroup{{14, 46}, {21, 47}}}, {30, []ecGroup{{29, 24}, {19, 25}}}, {30, []ecGroup{{11, 15}, {46, 16}}}}},
+	{[]int{6, 34, 62, 90, 118, 146}, [4]ecBlocks{{30, []ecGroup{{13, 115}, {6, 116}}}, {28, []ecGroup{{14, 46}, {23, 47}}}, {30, []ecGroup{{44, 24}, {7, 25}}}, {30, []ecGroup{{59, 16}, {1, 17}}}}},
+	{[]int{6, 30, 54, 78, 102, 126, 150}, [4]ecBlocks{{30, []ecGroup{{12, 121}, {7, 122}}}, {28, []ecGroup{{12, 47}, {26, 48}}}, {30, []ecGroup{{39, 24}, {14, 25}}}, {30, []ecGroup{{22, 15}, {41, 16}}}}},
+	{[]int{6, 24, 50, 76, 102, 128, 154}, [4]ecBlocks{{30, []ecGroup{{6, 121}, {14, 122}}}, {28, []ecGroup{{6, 47}, {34, 48}}}, {30, []ecGroup{{46, 24}, {10, 25}}}, {30, []ecGroup{{2, 15}, {64, 16}}}}},
+	{[]int{6, 28, 54, 80, 106, 132, 158}, [4]ecBlocks{{30, []ecGroup{{17, 122}, {4, 123}}}, {28, []ecGroup{{29, 46}, {14, 47}}}, {30, []ecGroup{{49, 24}, {10, 25}}}, {30, []ecGroup{{24, 15}, {46, 16}}}}},
+	{[]int{6, 32, 58, 84, 110, 136, 162}, [4]ecBlocks{{30, []ecGroup{{4, 122}, {18, 123}}}, {28, []ecGroup{{13, 46}, {32, 47}}}, {30, []ecGroup{{48, 24}, {14, 25}}}, {30, []ecGroup{{42, 15}, {32, 16}}}}},
+	{[]int{6, 26, 54, 82, 110, 138, 166}, [4]ecBlocks{{30, []ecGroup{{20, 117}, {4, 118}}}, {28, []ecGroup{{40, 47}, {7, 48}}}, {30, []ecGroup{{43, 24}, {22, 25}}}, {30, []ecGroup{{10, 15}, {67, 16}}}}},
+	{[]int{6, 30, 58, 86, 114, 142, 170}, [4]ecBlocks{{30, []ecGroup{{19, 118}, {6, 119}}}, {28, []ecGroup{{18, 47}, {31, 48}}}, {30, []ecGroup{{34, 24}, {34, 25}}}, {30, []ecGroup{{20, 15}, {61, 16}}}}},
+}
+
+// symbolSize returns the width in modules of a symbol of the given version
+func symbolSize(version int) int {
+	return 17 + 4*version
+}
+
+// totalCodewords returns the number of codewords a symbol of the given
+// version holds
+func totalCodewords(version int) int {
+	e := versions[version-1].ec[LevelL]
+	return e.dataCodewords() + e.ecPerBlock*e.blocks()
+}
+
+// levelBits maps levels to their two-bit format information code
+var levelBits = [4]uint32{LevelL: 1, LevelM: 0, LevelQ: 3, LevelH: 2}
+
+// bchRemainder returns the remainder of value (already shifted left by the
+// degree of poly) divided by poly over GF(2)
+func bchRemainder(value, poly uint32) uint32 {
+	degree := bits.Len32(poly) - 1
+	for bits.Len32(value) > degree {
+		value ^= poly << (bits.Len32(value) - 1 - degree)
+	}
+	return value
+}
+
+// formatBits returns the masked 15-bit format information for a level and
+// mask pattern
+func formatBits(level ErrorCorrectionLevel, mask int) uint32 {
+	data := levelBits[level]<<3 | uint32(mask)
+	return (data<<10 | bchRemainder(data<<10, 0x537)) ^ 0x5412
+}
+
+// versionBits returns the 18-bit version information for versions 7 and up
+func versionBits(version int) uint32 {
+	v := uint32(version)
+	return v<<12 | bchRemainder(v<<12, 0x1f25)
+}
+
+// functionPattern marks the modules of a symbol that hold finder, timing
+// and alignment patterns, format and version information, and the dark
+// module. Everything else carries data.
+func functionPattern(version int) [][]bool {
+	dim := symbolSize(version)
+	fn := make([][]bool, dim)
+	for i := range fn {
+		fn[i] = make([]bool, dim)
+	}
+	region := func(left, top, w, h int) {
+		for y := top; y < top+h; y++ {
+			for x := left; x < left+w; x++ {
+				fn[y][x] = true
+			}
+		}
+	}
+
+	// Finder patterns with separators and format information
+	region(0, 0, 9, 9)
+	region(dim-8, 0, 8, 9)
+	region(0, dim-8, 9, 8)
+
+	centres := versions[version-1].alignment
+	last := len(centres) - 1
+	for i, cy := range centres {
+		for j, cx := range centres {
+			// Skip the three positions that overlap finder patterns
+			if (i == 0 && (j == 0 || j == last)) || (i == last && j == 0) {
+				continue
+			}
+			region(cx-2, cy-2, 5, 5)
+		}
+	}
+
+	// Timing patterns
+	region(6, 9, 1, dim-17)
+	region(9, 6, dim-17, 1)
+
+	if version >= 7 {
+		region(dim-11, 0, 3, 6)
+		region(0, dim-11, 6, 3)
+	}
+	return fn
+}
+
+// maskBit reports whether mask pattern flips the module in row i, column j
+func maskBit(mask, i, j int) bool {
+	switch mask {
+	case 0:
+		return (i+j)%2 == 0
+	case 1:
+		return i%2 == 0
+	case 2:
+		return j%3 == 0
+	case 3:
+		return (i+j)%3 == 0
+	case 4:
+		return (i/2+j/3)%2 == 0
+	case 5:
+		return i*j%2+i*j%3 == 0
+	case 6:
+		return (i*j%2+i*j%3)%2 == 0
+	default:
+		return ((i+j)%2+i*j%3)%2 == 0
+	}
+}